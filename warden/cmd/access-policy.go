@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/slices"
 
@@ -10,15 +13,84 @@ import (
 
 // The list of users/teams, their permissions, and a strategy that should be applied.
 type accessPolicy struct {
-	Strategy    string           `yaml:"strategy"`
-	Permissions []userPermission `yaml:"permissions"`
-	Tags        []string         `yaml:"tags"`
+	Strategy          string           `yaml:"strategy"`
+	Permissions       []userPermission `yaml:"permissions"`
+	ExpiryWarningDays *int             `yaml:"expiryWarningDays"` // how early expiring access is warned about
+	Tags              []string         `yaml:"tags"`
 }
 
+// How many days before access expires it's warned about, unless the policy
+// says otherwise
+const DEFAULT_EXPIRY_WARNING_DAYS = 14
+
 // A user/team & permission pairing
 type userPermission struct {
 	User       string `yaml:"user"`
 	Permission string `yaml:"permission"`
+	Comparison string `yaml:"comparison"` // 'exact' (default), 'atLeast', or 'atMost'
+	Expires    string `yaml:"expires"`    // the last day the access is allowed, optional
+
+	expires time.Time
+}
+
+// GitHub's permissions, from least to most access. Custom roles rank the same
+// as the role they're based on.
+var permissionOrder = []string{"read", "triage", "push", "maintain", "admin"}
+
+// Returns the rank of a permission in permissionOrder, or -1 when it's not a
+// known permission or custom role
+func permissionRank(permission string, customRoles map[string]string) int {
+
+	if base, ok := customRoles[permission]; ok {
+		permission = base
+	}
+
+	return slices.Index(permissionOrder, policyPermission(permission))
+}
+
+// Whether a permission satisfies this userPermission
+func (this *userPermission) Satisfied(permission string, customRoles map[string]string) bool {
+
+	want := permissionRank(this.Permission, customRoles)
+	have := permissionRank(permission, customRoles)
+
+	switch this.Comparison {
+	case "atLeast":
+		return want != -1 && have != -1 && have >= want
+	case "atMost":
+		return want != -1 && have != -1 && have <= want
+	}
+
+	return policyPermission(this.Permission) == policyPermission(permission)
+}
+
+// Describes the permissions this userPermission allows, for messages
+func (this *userPermission) Wanted() string {
+
+	switch this.Comparison {
+	case "atLeast":
+		return this.Permission + " or higher"
+	case "atMost":
+		return this.Permission + " or lower"
+	}
+
+	return this.Permission
+}
+
+// Whether the access has expired. Like waivers, access is allowed through the
+// end of the day it expires.
+func (this *userPermission) Expired(now time.Time) bool {
+	return !this.expires.IsZero() && !now.Before(this.expires.AddDate(0, 0, 1))
+}
+
+// Returns the number of days left before the access expires, 0 being the day
+// it expires
+func (this *userPermission) DaysLeft(now time.Time) int {
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(this.expires.Sub(today).Hours() / 24)
 }
 
 // Whether or not this userPermission is for a team
@@ -56,8 +128,319 @@ func (this *userPermission) UserSlug() string {
 	return this.User[this.SlashPos()+1 : len(this.User)]
 }
 
-// Does the work to check an access policy against a repository
-func auditAccessPolicy(policy accessPolicy, repo *wardenRepo, teams []*github.Team) auditResults {
+// Makes sure every access permission uses a known comparison and parses
+// expiry dates
+func (this *PolicyFile) validateAccess() error {
+
+	for i := range this.Access {
+		for j := range this.Access[i].Permissions {
+
+			user := &this.Access[i].Permissions[j]
+
+			if !slices.Contains([]string{"", "exact", "atLeast", "atMost"}, user.Comparison) {
+				return fmt.Errorf("The comparison '%s' for '%s' isn't valid. Options are: exact, atLeast, atMost", user.Comparison, user.User)
+			}
+
+			if user.Expires == "" {
+				continue
+			}
+
+			expires, err := time.Parse(WAIVER_DATE_FORMAT, user.Expires)
+			if err != nil {
+				return fmt.Errorf("The expiry date '%s' for '%s' isn't valid. It needs to be in the YYYY-MM-DD format.", user.Expires, user.User)
+			}
+
+			user.expires = expires
+		}
+	}
+
+	return nil
+}
+
+// Checks the users and teams with access to the repository
+type accessCheck struct{}
+
+func init() {
+	registerCheck(accessCheck{})
+}
+
+func (this accessCheck) ID() string {
+	return "access"
+}
+
+func (this accessCheck) Description() string {
+	return "The users and teams with access to the repository match the policy."
+}
+
+func (this accessCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, accessPolicy := range policy.Access {
+		if tagsMatched(accessPolicy.Tags, snapshot.repo.Tags()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this accessCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	teams, err := snapshot.Teams()
+	if isNotFound(err) {
+
+		// considering this repo worked for other audits but not this, this likely
+		// means we don't have admin access in order to check teams
+		results.add(snapshot.repo, RESULT_WARNING, RULE_ACCESS_VISIBILITY, ERR_ACCESS_VISIBILITY)
+
+		return results, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// collaborators are only pulled when a policy lists users
+	var collaborators []*github.User
+
+	for _, accessPolicy := range policy.Access {
+
+		if !tagsMatched(accessPolicy.Tags, snapshot.repo.Tags()) || !accessPolicy.hasUsers() {
+			continue
+		}
+
+		collaborators, err = snapshot.Collaborators("direct")
+		if isNotFound(err) || isForbidden(err) {
+			results.add(snapshot.repo, RESULT_WARNING, RULE_ACCESS_VISIBILITY, ERR_ACCESS_VISIBILITY)
+			return results, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		break
+	}
+
+	// custom roles are only pulled when one is used. Orgs without them, and
+	// users, can't list them so they're left unranked.
+	var customRoles map[string]string
+
+	if usesCustomRoles(policy, snapshot.repo, teams, collaborators) {
+
+		customRoles, err = snapshot.CustomRoles()
+		if err != nil && !isNotFound(err) && !isForbidden(err) {
+			return nil, err
+		}
+	}
+
+	// the team hierarchy can't be seen for repos owned by users or without
+	// org access, in which case only direct access is considered
+	var parents map[string]string
+
+	if needsTeamParents(policy, snapshot.repo, teams) {
+
+		parents, err = snapshot.TeamParents()
+		if err != nil && !isNotFound(err) && !isForbidden(err) {
+			return nil, err
+		}
+	}
+
+	for _, accessPolicy := range policy.Access {
+		results.merge(auditAccessPolicy(accessPolicy, snapshot.repo, teams, collaborators, customRoles, parents, time.Now()))
+	}
+
+	return results, nil
+}
+
+// Whether a permission in the policy or on the repo is a custom role
+func usesCustomRoles(policy *PolicyFile, repo *wardenRepo, teams []*github.Team, collaborators []*github.User) bool {
+
+	var permissions []string
+
+	for _, accessPolicy := range policy.Access {
+		if tagsMatched(accessPolicy.Tags, repo.Tags()) {
+			for _, user := range accessPolicy.Permissions {
+				permissions = append(permissions, user.Permission)
+			}
+		}
+	}
+
+	for _, team := range teams {
+		permissions = append(permissions, team.GetPermission())
+	}
+
+	for _, collaborator := range collaborators {
+		permissions = append(permissions, collaborator.GetRoleName())
+	}
+
+	for _, permission := range permissions {
+		if permissionRank(permission, nil) == -1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Whether inheritance could matter, which is when a listed team could get
+// access from a parent or child team or the 'only' strategy finds a team that
+// isn't listed
+func needsTeamParents(policy *PolicyFile, repo *wardenRepo, teams []*github.Team) bool {
+
+	for _, accessPolicy := range policy.Access {
+
+		if !tagsMatched(accessPolicy.Tags, repo.Tags()) {
+			continue
+		}
+
+		listed := listedTeams(accessPolicy, repo, teams)
+
+		for _, user := range accessPolicy.Permissions {
+
+			if !user.IsTeam() || user.Owner() != repo.Owner {
+				continue
+			}
+
+			// a parent can only add access when it's on the repo as well
+			if teamPermission(teams, user.UserSlug()) == "" || len(teams) > 1 {
+				return true
+			}
+		}
+
+		if accessPolicy.Strategy == "only" && len(listed) < len(teams) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the ancestor of a team, among the given teams, with the most access
+// along with the inheritance path from that ancestor down to the team, such
+// as 'org/engineering > org/backend'. GitHub gives a team the highest
+// permission of its whole parent chain. The ancestor is nil when there isn't
+// one.
+func inheritedAccess(slug, org string, teams []*github.Team, parents map[string]string, customRoles map[string]string) (*github.Team, string) {
+
+	var best *github.Team
+	var bestPath string
+
+	path := []string{org + "/" + slug}
+
+	// the hierarchy is walked a limited number of levels in case of a cycle
+	for parent, i := parents[slug], 0; parent != "" && i < len(parents); parent, i = parents[parent], i+1 {
+
+		path = append([]string{org + "/" + parent}, path...)
+
+		for _, team := range teams {
+			if team.GetSlug() == parent && (best == nil || permissionRank(team.GetPermission(), customRoles) > permissionRank(best.GetPermission(), customRoles)) {
+				best = team
+				bestPath = strings.Join(path, " > ")
+			}
+		}
+	}
+
+	return best, bestPath
+}
+
+// Returns the descendant of a team, among the given teams, with the most
+// access along with the path from the team down to it, such as
+// 'org/engineering > org/backend'. Access is often granted to a child team
+// rather than the team a policy lists. The descendant is nil when there isn't
+// one.
+func grantedAccess(slug, org string, teams []*github.Team, parents map[string]string, customRoles map[string]string) (*github.Team, string) {
+
+	var best *github.Team
+	var bestPath string
+
+	for _, team := range teams {
+
+		path := []string{org + "/" + team.GetSlug()}
+
+		// the hierarchy is walked a limited number of levels in case of a cycle
+		for parent, i := parents[team.GetSlug()], 0; parent != "" && i < len(parents); parent, i = parents[parent], i+1 {
+
+			path = append([]string{org + "/" + parent}, path...)
+
+			if parent != slug {
+				continue
+			}
+
+			if best == nil || permissionRank(team.GetPermission(), customRoles) > permissionRank(best.GetPermission(), customRoles) {
+				best = team
+				bestPath = strings.Join(path, " > ")
+			}
+
+			break
+		}
+	}
+
+	return best, bestPath
+}
+
+// Returns the teams with access to the repo that the policy lists
+func listedTeams(policy accessPolicy, repo *wardenRepo, teams []*github.Team) []*github.Team {
+
+	var listed []*github.Team
+
+	for _, user := range policy.Permissions {
+
+		if user.IsUser() || user.Owner() != repo.Owner {
+			continue
+		}
+
+		for _, team := range teams {
+			if team.GetSlug() == user.UserSlug() {
+				listed = append(listed, team)
+			}
+		}
+	}
+
+	return listed
+}
+
+// Returns the permission a team has on the repo
+func teamPermission(teams []*github.Team, slug string) string {
+
+	for _, team := range teams {
+		if team.GetSlug() == slug {
+			return team.GetPermission()
+		}
+	}
+
+	return ""
+}
+
+// Whether the policy lists any users rather than only teams
+func (this accessPolicy) hasUsers() bool {
+
+	for _, user := range this.Permissions {
+		if user.IsUser() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The API calls 'read' access 'pull' for teams and 'push' access 'write' for
+// collaborators. This returns the name used in policies.
+func policyPermission(permission string) string {
+
+	switch permission {
+	case "pull":
+		return "read"
+	case "write":
+		return "push"
+	}
+
+	return permission
+}
+
+// Does the work to check an access policy against a repository. Teams are
+// checked against the repo's teams and users against its direct
+// collaborators. Custom roles are only needed to rank them and parents, the
+// parent slug of each team in the org, to resolve inherited access. Access
+// that expired before now should be gone.
+func auditAccessPolicy(policy accessPolicy, repo *wardenRepo, teams []*github.Team, collaborators []*github.User, customRoles map[string]string, parents map[string]string, now time.Time) auditResults {
 
 	var results auditResults
 
@@ -69,6 +452,7 @@ func auditAccessPolicy(policy accessPolicy, repo *wardenRepo, teams []*github.Te
 		results.add(
 			repo,
 			RESULT_ERROR,
+			RULE_ACCESS_STRATEGY,
 			ERR_ACCESS_STRATEGY,
 			policy.Strategy,
 		)
@@ -76,7 +460,13 @@ func auditAccessPolicy(policy accessPolicy, repo *wardenRepo, teams []*github.Te
 		return results
 	}
 
+	warningDays := DEFAULT_EXPIRY_WARNING_DAYS
+	if policy.ExpiryWarningDays != nil {
+		warningDays = *policy.ExpiryWarningDays
+	}
+
 	onlyMatches := make(map[string]bool)
+	onlyUsers := make(map[string]bool)
 
 	// for each user/team we're checking for
 	for _, user := range policy.Permissions {
@@ -84,40 +474,93 @@ func auditAccessPolicy(policy accessPolicy, repo *wardenRepo, teams []*github.Te
 		found := ""
 		matched := ""
 
-		// only checking teams for now
 		if user.IsUser() {
-			continue
-		}
 
-		// for teams, the team check only matters if we're in the same org
-		if user.Owner() != repo.Owner {
-			continue
-		}
+			for _, collaborator := range collaborators {
 
-		for _, team := range teams {
+				if strings.EqualFold(user.User, collaborator.GetLogin()) {
 
-			fullTeamName := strings.TrimSpace(repo.Owner + "/" + team.GetSlug())
+					found = user.User
+					onlyUsers[collaborator.GetLogin()] = true
 
-			if user.UserSlug() == team.GetSlug() {
+					if !user.Satisfied(collaborator.GetRoleName(), customRoles) {
+						matched = collaborator.GetRoleName()
+					}
+				} else if onlyUsers[collaborator.GetLogin()] != true {
+					onlyUsers[collaborator.GetLogin()] = false
+				}
+			}
+		} else {
 
-				found = user.UserSlug()
-				onlyMatches[fullTeamName] = true
+			// for teams, the team check only matters if we're in the same org
+			if user.Owner() != repo.Owner {
+				continue
+			}
 
-				if user.Permission != team.GetPermission() {
-					matched = team.GetPermission()
-				}
-			} else {
+			have := ""
 
-				if onlyMatches[fullTeamName] != true {
-					onlyMatches[fullTeamName] = false
+			for _, team := range teams {
+
+				fullTeamName := strings.TrimSpace(repo.Owner + "/" + team.GetSlug())
+
+				if user.UserSlug() == team.GetSlug() {
+
+					found = user.UserSlug()
+					have = team.GetPermission()
+					onlyMatches[fullTeamName] = true
+				} else {
+
+					if onlyMatches[fullTeamName] != true {
+						onlyMatches[fullTeamName] = false
+					}
 				}
 			}
+
+			// the team gets the access of a parent when it's more than its own
+			if parent, path := inheritedAccess(user.UserSlug(), repo.Owner, teams, parents, customRoles); parent != nil && (found == "" || permissionRank(parent.GetPermission(), customRoles) > permissionRank(have, customRoles)) {
+
+				found = user.UserSlug()
+				have = parent.GetPermission()
+
+				results.add(repo, RESULT_INFO, RULE_ACCESS_INHERITED, ERR_ACCESS_INHERITED, user.User, parent.GetPermission(), path)
+			}
+
+			// without access of its own, a team is given the access of a child
+			// team, which then isn't extra
+			if child, path := grantedAccess(user.UserSlug(), repo.Owner, teams, parents, customRoles); child != nil && found == "" {
+
+				found = user.UserSlug()
+				have = child.GetPermission()
+				onlyMatches[repo.Owner+"/"+child.GetSlug()] = true
+
+				results.add(repo, RESULT_INFO, RULE_ACCESS_INHERITED, ERR_ACCESS_INHERITED, user.User, child.GetPermission(), path)
+			}
+
+			if found != "" && !user.Satisfied(have, customRoles) {
+				matched = have
+			}
+		}
+
+		// expired access is only a problem while it's still there, and then
+		// it's reported instead of being missing, different, or extra
+		if user.Expired(now) {
+
+			if found != "" {
+				results.add(repo, RESULT_ERROR, RULE_ACCESS_EXPIRED, ERR_ACCESS_EXPIRED, user.User, user.Expires)
+			}
+
+			continue
+		}
+
+		if found != "" && !user.expires.IsZero() && user.DaysLeft(now) <= warningDays {
+			results.add(repo, RESULT_WARNING, RULE_ACCESS_EXPIRING, ERR_ACCESS_EXPIRING, user.User, user.Expires, user.DaysLeft(now))
 		}
 
 		if found == "" {
 			results.add(
 				repo,
 				RESULT_ERROR,
+				RULE_ACCESS_MISSING,
 				ERR_ACCESS_MISSING,
 				user.UserSlug(),
 			)
@@ -125,9 +568,10 @@ func auditAccessPolicy(policy accessPolicy, repo *wardenRepo, teams []*github.Te
 			results.add(
 				repo,
 				RESULT_ERROR,
+				RULE_ACCESS_DIFFERENT,
 				ERR_ACCESS_DIFFERENT,
 				found,
-				user.Permission,
+				user.Wanted(),
 				matched,
 			)
 		}
@@ -136,18 +580,49 @@ func auditAccessPolicy(policy accessPolicy, repo *wardenRepo, teams []*github.Te
 
 	if policy.Strategy == "only" {
 
-		for team, _ := range onlyMatches {
+		var extras []string
 
+		for team, _ := range onlyMatches {
 			if onlyMatches[team] == false {
+				extras = append(extras, team)
+			}
+		}
+
+		sort.Strings(extras)
+
+		for _, team := range extras {
+
+			// a child team of a listed team gets its access from the parent, so
+			// it's only extra when it was given more than that
+			slug := strings.TrimPrefix(team, repo.Owner+"/")
+
+			if parent, path := inheritedAccess(slug, repo.Owner, listedTeams(policy, repo, teams), parents, customRoles); parent != nil && permissionRank(teamPermission(teams, slug), customRoles) <= permissionRank(parent.GetPermission(), customRoles) {
+				results.add(repo, RESULT_INFO, RULE_ACCESS_INHERITED, ERR_ACCESS_INHERITED, team, parent.GetPermission(), path)
+				continue
+			}
+
+			results.add(
+				repo,
+				RESULT_ERROR,
+				RULE_ACCESS_EXTRA,
+				ERR_ACCESS_EXTRA,
+				team,
+			)
+		}
+
+		// direct collaborators are only checked when the policy lists users
+		for _, collaborator := range collaborators {
+
+			if policy.hasUsers() && !onlyUsers[collaborator.GetLogin()] {
 				results.add(
 					repo,
 					RESULT_ERROR,
+					RULE_ACCESS_EXTRA,
 					ERR_ACCESS_EXTRA,
-					team,
+					collaborator.GetLogin(),
 				)
 			}
 		}
-
 	}
 
 	return results
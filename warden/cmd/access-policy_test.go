@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestAccessCheck(t *testing.T) {
+
+	admin := map[string]string{"felicianotech": "admin"}
+
+	tcs := []struct {
+		strategy      string
+		teams         map[string]string
+		collaborators map[string]string
+		rules         []string
+	}{
+		{strategy: "available", teams: map[string]string{"writers": "push"}, collaborators: admin, rules: nil},
+		{strategy: "available", teams: map[string]string{"writers": "admin"}, collaborators: admin, rules: []string{RULE_ACCESS_DIFFERENT}},
+		{strategy: "available", teams: map[string]string{}, collaborators: admin, rules: []string{RULE_ACCESS_MISSING}},
+		{strategy: "only", teams: map[string]string{"writers": "push", "others": "pull"}, collaborators: admin, rules: []string{RULE_ACCESS_EXTRA}},
+		{strategy: "some", teams: map[string]string{"writers": "push"}, collaborators: admin, rules: []string{RULE_ACCESS_STRATEGY}},
+		{strategy: "available", teams: map[string]string{"writers": "push"}, collaborators: map[string]string{"FelicianoTech": "write"}, rules: []string{RULE_ACCESS_DIFFERENT}},
+		{strategy: "available", teams: map[string]string{"writers": "push"}, collaborators: map[string]string{"jdoe": "admin"}, rules: []string{RULE_ACCESS_MISSING}},
+		{strategy: "only", teams: map[string]string{"writers": "push"}, collaborators: map[string]string{"felicianotech": "admin", "jdoe": "admin"}, rules: []string{RULE_ACCESS_EXTRA}},
+	}
+
+	for i, tc := range tcs {
+
+		policy := &PolicyFile{Access: []accessPolicy{{
+			Strategy: tc.strategy,
+			Permissions: []userPermission{
+				{User: "felicianotech", Permission: "admin"},
+				{User: "felicianotech/writers", Permission: "push"},
+			},
+		}}}
+
+		snapshot := testSnapshot(t, nil, nil)
+		snapshot.loaded["teams"] = true
+		snapshot.loaded["teamParents"] = true
+
+		for slug, permission := range tc.teams {
+			snapshot.teams = append(snapshot.teams, &github.Team{Slug: github.String(slug), Permission: github.String(permission)})
+		}
+
+		snapshot.loaded["collaborators/direct"] = true
+
+		for login, role := range tc.collaborators {
+			snapshot.collaborators["direct"] = append(snapshot.collaborators["direct"], &github.User{Login: github.String(login), RoleName: github.String(role)})
+		}
+
+		if !(accessCheck{}).Applies(policy, snapshot) {
+			t.Fatalf("Case %d: The access check should apply.", i+1)
+		}
+
+		results, err := accessCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+}
+
+func TestAccessCheckVisibility(t *testing.T) {
+
+	policy := &PolicyFile{Access: []accessPolicy{{
+		Permissions: []userPermission{{User: "felicianotech/writers", Permission: "push"}},
+	}}}
+
+	snapshot := testSnapshot(t, nil, nil)
+	snapshot.loaded["teams"] = true
+	snapshot.teamsErr = &github.ErrorResponse{Response: &http.Response{StatusCode: 404}}
+
+	results, err := accessCheck{}.Evaluate(policy, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results.ByType(RESULT_WARNING)) != 1 || results[0].rule != RULE_ACCESS_VISIBILITY {
+		t.Errorf("Want a single '%s' warning, got %v", RULE_ACCESS_VISIBILITY, resultRules(results))
+	}
+
+	// collaborators need push access to be listed
+	policy.Access[0].Permissions = append(policy.Access[0].Permissions, userPermission{User: "felicianotech", Permission: "admin"})
+
+	snapshot.teamsErr = nil
+	snapshot.loaded["collaborators/direct"] = true
+	snapshot.collaboratorsErr["direct"] = &github.ErrorResponse{Response: &http.Response{StatusCode: 403}}
+
+	results, err = accessCheck{}.Evaluate(policy, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(resultRules(results), []string{RULE_ACCESS_VISIBILITY}) {
+		t.Errorf("Want a single '%s' warning for collaborators, got %v", RULE_ACCESS_VISIBILITY, resultRules(results))
+	}
+}
+
+func TestAccessCheckTags(t *testing.T) {
+
+	policy := &PolicyFile{Access: []accessPolicy{{
+		Permissions: []userPermission{{User: "felicianotech/writers", Permission: "push"}},
+		Tags:        []string{"hugo"},
+	}}}
+
+	if (accessCheck{}).Applies(policy, testSnapshot(t, []string{"go"}, nil)) {
+		t.Error("The access check shouldn't apply to a repo without matching tags.")
+	}
+
+	if !(accessCheck{}).Applies(policy, testSnapshot(t, []string{"hugo"}, nil)) {
+		t.Error("The access check should apply to a repo with matching tags.")
+	}
+}
+
+func TestUserPermissionSatisfied(t *testing.T) {
+
+	customRoles := map[string]string{"security-engineer": "maintain", "docs-writer": "write"}
+
+	tcs := []struct {
+		user       userPermission
+		permission string
+		satisfied  bool
+	}{
+		{user: userPermission{Permission: "push"}, permission: "push", satisfied: true},
+		{user: userPermission{Permission: "push"}, permission: "admin", satisfied: false},
+		{user: userPermission{Permission: "read"}, permission: "pull", satisfied: true},
+		{user: userPermission{Permission: "push", Comparison: "atLeast"}, permission: "admin", satisfied: true},
+		{user: userPermission{Permission: "maintain", Comparison: "atLeast"}, permission: "write", satisfied: false},
+		{user: userPermission{Permission: "push", Comparison: "atMost"}, permission: "triage", satisfied: true},
+		{user: userPermission{Permission: "push", Comparison: "atMost"}, permission: "maintain", satisfied: false},
+		{user: userPermission{Permission: "maintain", Comparison: "atLeast"}, permission: "security-engineer", satisfied: true},
+		{user: userPermission{Permission: "docs-writer", Comparison: "atMost"}, permission: "push", satisfied: true},
+		{user: userPermission{Permission: "docs-writer"}, permission: "docs-writer", satisfied: true},
+		{user: userPermission{Permission: "push", Comparison: "atLeast"}, permission: "unknown-role", satisfied: false},
+	}
+
+	for i, tc := range tcs {
+		if satisfied := tc.user.Satisfied(tc.permission, customRoles); satisfied != tc.satisfied {
+			t.Errorf("Case %d: Want satisfied %t for '%s', got %t", i+1, tc.satisfied, tc.permission, satisfied)
+		}
+	}
+}
+
+func TestAccessCheckComparisons(t *testing.T) {
+
+	policy := &PolicyFile{Access: []accessPolicy{{
+		Permissions: []userPermission{
+			{User: "felicianotech/platform", Permission: "maintain", Comparison: "atLeast"},
+			{User: "felicianotech/writers", Permission: "push", Comparison: "atMost"},
+		},
+	}}}
+
+	snapshot := testSnapshot(t, nil, nil)
+	snapshot.loaded["teams"] = true
+	snapshot.teams = []*github.Team{
+		{Slug: github.String("platform"), Permission: github.String("admin")},
+		{Slug: github.String("writers"), Permission: github.String("release-manager")},
+	}
+	snapshot.loaded["customRoles"] = true
+	snapshot.customRoles = map[string]string{"release-manager": "maintain"}
+	snapshot.loaded["teamParents"] = true
+
+	results, err := accessCheck{}.Evaluate(policy, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].String() != "The user/team 'writers' should have the permission 'push or lower', not 'release-manager'." {
+		t.Errorf("Want only the writers team to exceed its permission, got %v", results)
+	}
+
+	policy.Access[0].Permissions[0].Comparison = "above"
+	if policy.validateAccess() == nil {
+		t.Error("An unknown comparison should fail validation.")
+	}
+}
+
+func TestAccessCheckInheritance(t *testing.T) {
+
+	parents := map[string]string{"backend": "engineering", "api": "backend", "docs": "writers"}
+
+	tcs := []struct {
+		strategy string
+		teams    map[string]string
+		rules    []string
+	}{
+		// engineering is granted push through its child backend, and api
+		// inherits push through backend
+		{strategy: "available", teams: map[string]string{"backend": "push"}, rules: []string{RULE_ACCESS_INHERITED, RULE_ACCESS_INHERITED}},
+		// a child team that's granted the access isn't extra
+		{strategy: "only", teams: map[string]string{"backend": "push"}, rules: []string{RULE_ACCESS_INHERITED, RULE_ACCESS_INHERITED}},
+		{strategy: "only", teams: map[string]string{"backend": "admin"}, rules: []string{RULE_ACCESS_INHERITED, RULE_ACCESS_DIFFERENT, RULE_ACCESS_INHERITED, RULE_ACCESS_DIFFERENT}},
+		{strategy: "available", teams: map[string]string{"engineering": "push"}, rules: []string{RULE_ACCESS_INHERITED}},
+		{strategy: "available", teams: map[string]string{"engineering": "admin"}, rules: []string{RULE_ACCESS_DIFFERENT, RULE_ACCESS_INHERITED, RULE_ACCESS_DIFFERENT}},
+		{strategy: "only", teams: map[string]string{"engineering": "push", "backend": "push"}, rules: []string{RULE_ACCESS_INHERITED, RULE_ACCESS_INHERITED}},
+		{strategy: "only", teams: map[string]string{"engineering": "push", "backend": "admin"}, rules: []string{RULE_ACCESS_INHERITED, RULE_ACCESS_DIFFERENT, RULE_ACCESS_EXTRA}},
+		// the most access along the parent chain wins, not the closest parent
+		{strategy: "available", teams: map[string]string{"engineering": "admin", "backend": "push"}, rules: []string{RULE_ACCESS_DIFFERENT, RULE_ACCESS_INHERITED, RULE_ACCESS_DIFFERENT}},
+		// as does a parent with more access than the team's own
+		{strategy: "available", teams: map[string]string{"backend": "push", "api": "pull"}, rules: []string{RULE_ACCESS_INHERITED, RULE_ACCESS_INHERITED}},
+	}
+
+	for i, tc := range tcs {
+
+		policy := &PolicyFile{Access: []accessPolicy{{
+			Strategy: tc.strategy,
+			Permissions: []userPermission{
+				{User: "felicianotech/engineering", Permission: "push"},
+				{User: "felicianotech/api", Permission: "push"},
+			},
+		}}}
+
+		snapshot := testSnapshot(t, nil, nil)
+		snapshot.loaded["teams"] = true
+		snapshot.loaded["teamParents"] = true
+		snapshot.teamParents = parents
+
+		for slug, permission := range tc.teams {
+			snapshot.teams = append(snapshot.teams, &github.Team{Slug: github.String(slug), Permission: github.String(permission)})
+		}
+
+		results, err := accessCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+
+	_, path := inheritedAccess("api", "felicianotech", []*github.Team{{Slug: github.String("engineering")}}, parents, nil)
+	if path != "felicianotech/engineering > felicianotech/backend > felicianotech/api" {
+		t.Errorf("Want the full inheritance path, got '%s'", path)
+	}
+
+	_, path = grantedAccess("engineering", "felicianotech", []*github.Team{{Slug: github.String("api")}}, parents, nil)
+	if path != "felicianotech/engineering > felicianotech/backend > felicianotech/api" {
+		t.Errorf("Want the full path down to the child team, got '%s'", path)
+	}
+}
+
+func TestAccessCheckExpiry(t *testing.T) {
+
+	now := time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC)
+	repo := testSnapshot(t, nil, nil).repo
+
+	tcs := []struct {
+		strategy      string
+		expires       string
+		collaborators map[string]string
+		rules         []string
+	}{
+		{strategy: "available", expires: "2026-07-30", collaborators: map[string]string{"contractor": "push"}, rules: nil},
+		{strategy: "available", expires: "2026-06-20", collaborators: map[string]string{"contractor": "push"}, rules: []string{RULE_ACCESS_EXPIRING}},
+		{strategy: "available", expires: "2026-06-10", collaborators: map[string]string{"contractor": "admin"}, rules: []string{RULE_ACCESS_EXPIRING, RULE_ACCESS_DIFFERENT}},
+		{strategy: "available", expires: "2026-06-09", collaborators: map[string]string{"contractor": "push"}, rules: []string{RULE_ACCESS_EXPIRED}},
+		{strategy: "available", expires: "2026-06-09", collaborators: map[string]string{}, rules: nil},
+		{strategy: "only", expires: "2026-06-09", collaborators: map[string]string{"contractor": "push"}, rules: []string{RULE_ACCESS_EXPIRED}},
+	}
+
+	for i, tc := range tcs {
+
+		policy := &PolicyFile{Access: []accessPolicy{{
+			Strategy:    tc.strategy,
+			Permissions: []userPermission{{User: "contractor", Permission: "push", Expires: tc.expires}},
+		}}}
+
+		err := policy.validateAccess()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var collaborators []*github.User
+		for login, role := range tc.collaborators {
+			collaborators = append(collaborators, &github.User{Login: github.String(login), RoleName: github.String(role)})
+		}
+
+		results := auditAccessPolicy(policy.Access[0], repo, nil, collaborators, nil, nil, now)
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+
+	policy := &PolicyFile{Access: []accessPolicy{{
+		Permissions:       []userPermission{{User: "contractor", Permission: "push", Expires: "2026-06-20"}},
+		ExpiryWarningDays: github.Int(7),
+	}}}
+
+	if err := policy.validateAccess(); err != nil {
+		t.Fatal(err)
+	}
+
+	contractor := []*github.User{{Login: github.String("contractor"), RoleName: github.String("write")}}
+
+	if results := auditAccessPolicy(policy.Access[0], repo, nil, contractor, nil, nil, now); len(results) != 0 {
+		t.Errorf("Access expiring after the warning days shouldn't be reported, got %v", results)
+	}
+
+	policy.Access[0].Permissions[0].Expires = "20/06/2026"
+	if policy.validateAccess() == nil {
+		t.Error("An expiry date in the wrong format should fail validation.")
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+// The security tools that report alerts, in the order they're reported
+var alertTools = []string{"dependabot", "codeScanning", "secretScanning"}
+
+// Alert severities, from lowest to highest
+var alertSeverities = []string{"low", "medium", "high", "critical"}
+
+// A limit on the open security alerts of a repository. When more than one
+// alert policy covers the same tool, later policies override earlier ones.
+type alertPolicy struct {
+	Tool     string   `yaml:"tool"`     // 'dependabot', 'codeScanning', 'secretScanning', or 'all'
+	Severity string   `yaml:"severity"` // alerts of this severity or higher fail
+	MaxAge   int      `yaml:"maxAge"`   // the days an alert can stay open, 0 for none
+	Tags     []string `yaml:"tags"`
+}
+
+// Whether the policy limits the alerts of a tool
+func (this alertPolicy) covers(tool string) bool {
+	return this.Tool == tool || this.Tool == "all"
+}
+
+// Makes sure every alert policy uses a known tool and severity
+func (this *PolicyFile) validateAlerts() error {
+
+	for _, aPolicy := range this.Alerts {
+
+		if aPolicy.Tool != "all" && !slices.Contains(alertTools, aPolicy.Tool) {
+			return fmt.Errorf("The alert tool '%s' isn't valid. Options are: %s, all", aPolicy.Tool, strings.Join(alertTools, ", "))
+		}
+
+		if !slices.Contains(alertSeverities, aPolicy.Severity) {
+			return fmt.Errorf("The alert severity '%s' for '%s' isn't valid. Options are: %s", aPolicy.Severity, aPolicy.Tool, strings.Join(alertSeverities, ", "))
+		}
+	}
+
+	return nil
+}
+
+// An open alert from one of the security tools
+type securityAlert struct {
+	Severity string // one of alertSeverities
+	Created  time.Time
+	URL      string
+}
+
+// Returns the severity of a code scanning alert. Security alerts carry their
+// own severity while others are mapped from the severity of their rule.
+func codeScanningSeverity(rule *github.Rule) string {
+
+	if level := rule.GetSecuritySeverityLevel(); level != "" {
+		return level
+	}
+
+	switch rule.GetSeverity() {
+	case "error":
+		return "high"
+	case "warning":
+		return "medium"
+	}
+
+	return "low"
+}
+
+// Checks the open security alerts of the repository against the policy
+type alertsCheck struct{}
+
+func init() {
+	registerCheck(alertsCheck{})
+}
+
+func (this alertsCheck) ID() string {
+	return "alerts"
+}
+
+func (this alertsCheck) Description() string {
+	return "The repository has no open security alerts above the severity and age the policy allows."
+}
+
+func (this alertsCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, aPolicy := range policy.Alerts {
+		if tagsMatched(aPolicy.Tags, snapshot.repo.Tags()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this alertsCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	now := time.Now()
+
+	for _, tool := range alertTools {
+
+		var toolPolicy *alertPolicy
+
+		for i, aPolicy := range policy.Alerts {
+			if aPolicy.covers(tool) && tagsMatched(aPolicy.Tags, snapshot.repo.Tags()) {
+				toolPolicy = &policy.Alerts[i]
+			}
+		}
+
+		if toolPolicy == nil {
+			continue
+		}
+
+		// the tool being disabled also results in a not found or forbidden error
+		alerts, err := snapshot.Alerts(tool)
+		if isForbidden(err) || isNotFound(err) {
+			results.add(snapshot.repo, RESULT_WARNING, RULE_ALERTS_VISIBILITY, ERR_ALERTS_VISIBILITY, tool)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		results.merge(auditAlerts(*toolPolicy, snapshot.repo, tool, alerts, now))
+	}
+
+	return results, nil
+}
+
+// Reports the alerts of a tool that break the policy, by severity with the
+// highest first
+func auditAlerts(policy alertPolicy, repo *wardenRepo, tool string, alerts []*securityAlert, now time.Time) auditResults {
+
+	var results auditResults
+
+	// the severity is validated when the policy is loaded
+	threshold := slices.Index(alertSeverities, policy.Severity)
+
+	urls := make(map[string][]string)
+
+	for _, alert := range alerts {
+
+		if slices.Index(alertSeverities, alert.Severity) < threshold {
+			continue
+		}
+
+		if now.Sub(alert.Created) <= time.Duration(policy.MaxAge)*24*time.Hour {
+			continue
+		}
+
+		urls[alert.Severity] = append(urls[alert.Severity], "    > "+alert.URL)
+	}
+
+	for i := len(alertSeverities) - 1; i >= threshold; i-- {
+
+		severity := alertSeverities[i]
+
+		if len(urls[severity]) == 0 {
+			continue
+		}
+
+		results.add(
+			repo,
+			RESULT_ERROR,
+			RULE_ALERTS_OPEN,
+			ERR_ALERTS_OPEN,
+			tool,
+			severity,
+			len(urls[severity]),
+			policy.MaxAge,
+			strings.Join(urls[severity], "\n"),
+		)
+	}
+
+	return results
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestAuditAlerts(t *testing.T) {
+
+	now := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+	repo := testSnapshot(t, nil, nil).repo
+
+	alerts := []*securityAlert{
+		{Severity: "critical", Created: now.AddDate(0, 0, -10), URL: "https://github.com/felicianotech/sonar/security/dependabot/1"},
+		{Severity: "critical", Created: now.AddDate(0, 0, -2), URL: "https://github.com/felicianotech/sonar/security/dependabot/2"},
+		{Severity: "high", Created: now.AddDate(0, 0, -30), URL: "https://github.com/felicianotech/sonar/security/dependabot/3"},
+		{Severity: "low", Created: now.AddDate(0, 0, -30), URL: "https://github.com/felicianotech/sonar/security/dependabot/4"},
+	}
+
+	tcs := []struct {
+		policy alertPolicy
+		counts []int
+	}{
+		{alertPolicy{Severity: "critical", MaxAge: 7}, []int{1}},
+		{alertPolicy{Severity: "critical"}, []int{2}},
+		{alertPolicy{Severity: "high", MaxAge: 7}, []int{1, 1}},
+		{alertPolicy{Severity: "low", MaxAge: 60}, nil},
+	}
+
+	for i, tc := range tcs {
+
+		var counts []int
+
+		for _, result := range auditAlerts(tc.policy, repo, "dependabot", alerts, now) {
+
+			if result.rule != RULE_ALERTS_OPEN {
+				t.Errorf("Case %d: Want rule %s, got %s", i+1, RULE_ALERTS_OPEN, result.rule)
+			}
+
+			counts = append(counts, result.values[2].(int))
+		}
+
+		if !slices.Equal(counts, tc.counts) {
+			t.Errorf("Case %d: Want counts %v, got %v", i+1, tc.counts, counts)
+		}
+	}
+}
+
+func TestAlertsCheck(t *testing.T) {
+
+	policy := &PolicyFile{Alerts: []alertPolicy{
+		{Tool: "all", Severity: "high"},
+		{Tool: "secretScanning", Severity: "low", Tags: []string{"go"}},
+	}}
+
+	snapshot := testSnapshot(t, []string{"go"}, nil)
+	snapshot.alerts["dependabot"] = nil
+	snapshot.alerts["codeScanning"] = []*securityAlert{{Severity: "medium"}}
+	snapshot.alerts["secretScanning"] = []*securityAlert{{Severity: "critical"}}
+
+	results, err := alertsCheck{}.Evaluate(policy, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].values[0] != "secretScanning" {
+		t.Errorf("Want a single secret scanning result, got %v", results)
+	}
+
+	if severity := codeScanningSeverity(&github.Rule{Severity: github.String("error")}); severity != "high" {
+		t.Errorf("Want code scanning errors to be high severity, got %s", severity)
+	}
+}
+
+func TestDependabotAlertsPaging(t *testing.T) {
+
+	snapshot := testSnapshot(t, nil, nil)
+	snapshot.client = testClient(t, func(w http.ResponseWriter, r *http.Request) {
+
+		// the cursor of the next page is given in the Link header
+		cursor := r.URL.Query().Get("after")
+		next := map[string]string{"": "Y3Vyc29yOjI=", "Y3Vyc29yOjI=": "Y3Vyc29yOjM="}[cursor]
+
+		if next != "" {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?after=%s&per_page=100&state=open>; rel="next"`, r.Host, r.URL.Path, next))
+		}
+
+		fmt.Fprintf(w, `[{"html_url": "https://github.com/felicianotech/sonar/security/dependabot/%s", "security_advisory": {"severity": "high"}}]`, cursor)
+	})
+
+	alerts, err := snapshot.Alerts("dependabot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(alerts) != 3 {
+		t.Errorf("Want the alerts from every page, got %d", len(alerts))
+	}
+}
+
+func TestValidateAlerts(t *testing.T) {
+
+	tcs := []struct {
+		policy alertPolicy
+		valid  bool
+	}{
+		{alertPolicy{Tool: "all", Severity: "high"}, true},
+		{alertPolicy{Tool: "dependabot", Severity: "critical"}, true},
+		{alertPolicy{Tool: "dependabot", Severity: "severe"}, false},
+		{alertPolicy{Tool: "dependabot"}, false},
+		{alertPolicy{Tool: "trivy", Severity: "low"}, false},
+	}
+
+	for i, tc := range tcs {
+
+		policy := &PolicyFile{Alerts: []alertPolicy{tc.policy}}
+
+		if err := policy.validateAlerts(); (err == nil) != tc.valid {
+			t.Errorf("Case %d: Want valid to be %t, got %v", i+1, tc.valid, err)
+		}
+	}
+}
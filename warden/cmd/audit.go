@@ -5,24 +5,44 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/exp/slices"
-	"golang.org/x/oauth2"
 
 	"github.com/google/go-github/v53/github"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var (
-	branchFl string
+	branchFl        string
+	outputFl        string
+	outputFileFl    string
+	concurrencyFl   int
+	baselineFl      string
+	writeBaselineFl string
 
 	auditCmd = &cobra.Command{
 		Use:   "audit",
 		Short: "Validates that 1 or more repos meet a set of policy",
 		RunE: func(cmd *cobra.Command, args []string) error {
 
-			var results auditResults
+			if !slices.Contains(outputFormats, outputFl) {
+				return fmt.Errorf("'%s' is not a valid output format. Options are: %s", outputFl, strings.Join(outputFormats, ", "))
+			}
+
+			if outputFl == "text" && outputFileFl != "" {
+				return errors.New("The '--output-file' flag can't be used with the text output format.")
+			}
+
+			if concurrencyFl < 1 {
+				return errors.New("The '--concurrency' flag needs to be at least 1.")
+			}
+
+			if baselineFl != "" && writeBaselineFl != "" {
+				return errors.New("The '--baseline' and '--write-baseline' flags can't be used together.")
+			}
 
 			repoFile, _, err := loadRepositoriesFile(repositoriesFileFl)
 			if err != nil {
@@ -34,16 +54,15 @@ var (
 				return err
 			}
 
-			ghToken := viper.GetString("GH_TOKEN")
-			if ghToken == "" {
-				return errors.New("GitHub credentials were not found. Please run `warden configure`.")
+			waivers, err := loadWaiversFile(waiversFileFl)
+			if err != nil {
+				return err
 			}
 
-			ts := oauth2.StaticTokenSource(
-				&oauth2.Token{AccessToken: ghToken},
-			)
-			tc := oauth2.NewClient(context.Background(), ts)
-			client := github.NewClient(tc)
+			client, err := newGitHubClient(maxWaitFl)
+			if err != nil {
+				return err
+			}
 
 			group, err := repoFile.Group(groupFl)
 			if err != nil {
@@ -55,201 +74,191 @@ var (
 				return err
 			}
 
-			for _, repo := range repos {
+			results, err := auditRepos(repos, policy, client, concurrencyFl)
+			if err != nil {
+				return err
+			}
 
-				var currentBranch string
+			results = waivers.apply(results, time.Now())
 
-				repoResp, _, _ := client.Repositories.Get(context.Background(), repo.Owner, repo.Name)
+			if writeBaselineFl != "" {
 
-				if repoResp.GetArchived() != policy.Archived {
-					continue
+				err = writeBaselineFile(writeBaselineFl, results)
+				if err != nil {
+					return err
 				}
 
-				// check if we're working with the default branch or a specific one
-				if branchFl != "" {
-					currentBranch = branchFl
-				} else {
-					currentBranch = repoResp.GetDefaultBranch()
-				}
+				fmt.Fprintf(os.Stderr, "The baseline file %s has been written.\n", writeBaselineFl)
+			}
 
-				if repoResp.GetDefaultBranch() != policy.DefaultBranch {
-					results.add(
-						repo,
-						RESULT_ERROR,
-						ERR_BRANCH_DEFAULT,
-						policy.DefaultBranch,
-						repoResp.GetDefaultBranch(),
-					)
-				}
+			if baselineFl != "" {
 
-				// if license is to be checked...
-				if policy.License != nil && policy.License.Scope == repoResp.GetVisibility() || policy.License.Scope == "all" {
-					if repoResp.GetLicense().GetKey() == "" {
-						results.add(
-							repo,
-							RESULT_ERROR,
-							ERR_LICENSE_MISSING,
-						)
-					} else if !slices.Contains(policy.License.Names, repoResp.GetLicense().GetKey()) {
-						results.add(
-							repo,
-							RESULT_ERROR,
-							ERR_LICENSE_DIFFERENT,
-							policy.License.Names,
-							repoResp.GetLicense().GetKey(),
-						)
-					}
+				baseline, err := loadBaselineFile(baselineFl)
+				if err != nil {
+					return err
 				}
 
-				// if label checks are to happen
-				if len(policy.Labels) > 0 {
+				results = baseline.apply(results, repos, policy.Rules)
+			}
+
+			switch outputFl {
+			case "json", "sarif", "junit":
+				out := os.Stdout
 
-					labels, _, err := client.Issues.ListLabels(context.Background(), repo.Owner, repo.Name, nil)
+				if outputFileFl != "" {
+					out, err = os.Create(outputFileFl)
 					if err != nil {
 						return err
 					}
+					defer out.Close()
+				}
 
-					if policy.LabelStrategy == "available" || policy.LabelStrategy == "" {
-
-						// for each labal we're checking for
-						for _, label := range policy.Labels {
-
-							found := false
-
-							for _, iLabel := range labels {
-
-								if label == iLabel.GetName() {
-									found = true
-								}
-							}
-
-							if !found {
-								results.add(
-									repo,
-									RESULT_ERROR,
-									ERR_LABEL_MISSING,
-									label,
-								)
-							}
-						}
-					} else if policy.LabelStrategy == "only" {
-
-						// for each labal we're checking for
-						for _, iLabel := range labels {
-
-							found := ""
-
-							for _, label := range policy.Labels {
-
-								if label == iLabel.GetName() {
-									found = label
-								}
-							}
-
-							if found != "" {
-								results.add(
-									repo,
-									RESULT_ERROR,
-									ERR_LABEL_EXTRA,
-									found,
-								)
-							}
-						}
-					} else {
-						return errors.New("The labelStrategy of " + policy.LabelStrategy + " isn't valid.")
-					}
+				switch outputFl {
+				case "json":
+					err = writeJSONReport(out, results, len(repos), groupFl)
+				case "sarif":
+					err = writeSARIFReport(out, results, baselineFl != "")
+				case "junit":
+					err = writeJUnitReport(out, results)
+				}
+				if err != nil {
+					return err
+				}
 
+				if len(results.ByType(RESULT_ERROR)) > 0 {
+					return fmt.Errorf("The audit failed with %d policy failures.", len(results.ByType(RESULT_ERROR)))
 				}
+			default:
+				writeTextReport(results, len(repos), groupFl)
 
-				// if access permissions are to be checked...
-				if len(policy.Access) > 0 {
+				if len(results.ByType(RESULT_ERROR)) > 0 {
+					return fmt.Errorf("The audit failed. Above are the policy failures, by repository.\n")
+				}
 
-					teams, resp, err := client.Repositories.ListTeams(context.Background(), repo.Owner, repo.Name, nil)
-					if resp.StatusCode == 404 {
+				fmt.Println("The audit completed successfully.")
+			}
 
-						// considering this repo worked for other audits but not this, this likely
-						// means we don't have admin access in order to check teams
-						results.add(repo, RESULT_WARNING, fmt.Sprintf("Couldn't pull teams. There's a visibility issue here."))
+			return nil
+		},
+	}
+)
 
-						// skip the rest
-						policy.Access = nil
-					} else if err != nil {
-						return err
-					}
+func init() {
 
-					for _, accessPolicy := range policy.Access {
-						results.merge(auditAccessPolicy(accessPolicy, repo, teams))
-					}
-				}
+	AddChildrenFlag(auditCmd)
+	AddGroupFlag(auditCmd)
+	AddMaxWaitFlag(auditCmd)
+	AddPolicyFileFlag(auditCmd)
+	AddRepositoriesFileFlag(auditCmd)
+	AddWaiversFileFlag(auditCmd)
 
-				// if codeowners are to to be checked...
-				if len(policy.Codeowners) > 0 {
+	auditCmd.PersistentFlags().StringVar(&baselineFl, "baseline", "", "only fail on violations that aren't in this baseline file")
+	auditCmd.PersistentFlags().StringVar(&writeBaselineFl, "write-baseline", "", "record the current violations to this baseline file")
 
-					for _, coPolicy := range policy.Codeowners {
-						results.merge(auditCodeownersPolicy(coPolicy, repo, client, currentBranch))
-					}
-				}
-			}
+	auditCmd.PersistentFlags().StringVar(&branchFl, "branch", "", "git branch to audit (for applicable polcies")
+	auditCmd.PersistentFlags().StringVar(&outputFl, "output", "text", "format for the audit results, 'text', 'json', 'sarif', or 'junit'")
+	auditCmd.PersistentFlags().IntVar(&concurrencyFl, "concurrency", 1, "number of repositories to audit at the same time")
+	auditCmd.PersistentFlags().StringVar(&outputFileFl, "output-file", "", "write the audit results to this file instead of stdout (not supported by text)")
 
-			fmt.Printf(
-				`======================================================================
-                         Warden Audit Results
+	rootCmd.AddCommand(auditCmd)
+}
 
-     errors: %d     warnings: %d     repos: %d     group: %s
-======================================================================
+// Audits each repository using the given number of workers. Results are
+// returned in the same order as the repositories.
+func auditRepos(repos []*wardenRepo, policy *PolicyFile, client *github.Client, concurrency int) (auditResults, error) {
 
-`,
-				len(results.ByType(RESULT_ERROR)),
-				len(results.ByType(RESULT_WARNING)),
-				len(repos),
-				groupFl,
-			)
+	var results auditResults
 
-			if len(results) > 0 {
+	// org wide data is shared by the workers
+	orgs := newOrgCache()
 
-				var curRepo string
+	repoResults := make([]auditResults, len(repos))
+	repoErrs := make([]error, len(repos))
+	jobs := make(chan int)
 
-				for _, result := range results {
+	var wg sync.WaitGroup
 
-					// print repo URL whenever we move to the next one
-					if curRepo != result.repository.ToHTTPS() {
+	// each worker audits one repo at a time, storing results by the repo's
+	// index so that output keeps the order from the repositories file
+	for i := 0; i < concurrency; i++ {
 
-						curRepo = result.repository.ToHTTPS()
-						fmt.Fprintf(os.Stderr, "%s:\n", curRepo)
-					}
+		wg.Add(1)
 
-					switch result.resultType {
-					case RESULT_ERROR:
-						fmt.Fprintf(os.Stderr, "  \033[31mx\033[0m %s\n", result)
-					case RESULT_WARNING:
-						fmt.Printf("  \033[33mo\033[0m %s\n", result)
-					}
-				}
+		go func() {
+			defer wg.Done()
 
-				fmt.Println("") // intentional
+			for j := range jobs {
+				repoResults[j], repoErrs[j] = auditRepo(repos[j], policy, client, orgs)
 			}
+		}()
+	}
 
-			if len(results.ByType(RESULT_ERROR)) > 0 {
-				return fmt.Errorf("The audit failed. Above are the policy failures, by repository.\n")
-			}
+	for i := range repos {
+		jobs <- i
+	}
 
-			fmt.Println("The audit completed successfully.")
+	close(jobs)
+	wg.Wait()
 
-			return nil
-		},
+	for i := range repos {
+
+		if repoErrs[i] != nil {
+			return nil, repoErrs[i]
+		}
+
+		results.merge(repoResults[i])
 	}
-)
 
-func init() {
+	return results, nil
+}
 
-	AddChildrenFlag(auditCmd)
-	AddGroupFlag(auditCmd)
-	AddPolicyFileFlag(auditCmd)
-	AddRepositoriesFileFlag(auditCmd)
+// Audits a single repository against the policy. The policy and org data are
+// shared between concurrent audits, and the policy must not be modified.
+func auditRepo(repo *wardenRepo, policy *PolicyFile, client *github.Client, orgs *orgCache) (auditResults, error) {
 
-	auditCmd.PersistentFlags().StringVar(&branchFl, "branch", "", "git branch to audit (for applicable polcies")
+	var results auditResults
 
-	rootCmd.AddCommand(auditCmd)
+	var currentBranch string
+	var evaluated []string
+
+	repoResp, _, err := client.Repositories.Get(context.Background(), repo.Owner, repo.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if repoResp.GetArchived() != policy.Archived {
+		return nil, nil
+	}
+
+	// check if we're working with the default branch or a specific one
+	if branchFl != "" {
+		currentBranch = branchFl
+	} else {
+		currentBranch = repoResp.GetDefaultBranch()
+	}
+
+	snapshot := newRepoSnapshot(repo, repoResp, currentBranch, client, orgs)
+
+	for _, check := range checks {
+
+		if !check.Applies(policy, snapshot) {
+			continue
+		}
+
+		evaluated = append(evaluated, check.ID())
+
+		checkResults, err := check.Evaluate(policy, snapshot)
+		if err != nil {
+			return nil, err
+		}
+
+		results.merge(checkResults)
+	}
+
+	results = applyRulePolicies(results, policy.Rules)
+	results.addPasses(repo, evaluated)
+
+	return results, nil
 }
 
 func tagsMatched(policyTags, repoTags []string) bool {
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/repowarden/cli/warden/vcsurl"
+)
+
+func TestAuditReposOrder(t *testing.T) {
+
+	names := []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel"}
+
+	// earlier repos answer slower so that workers finish out of order
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		name := strings.TrimPrefix(r.URL.Path, "/repos/felicianotech/")
+
+		for i, n := range names {
+			if n == name {
+				time.Sleep(time.Duration(len(names)-i) * 5 * time.Millisecond)
+			}
+		}
+
+		fmt.Fprintf(w, `{"name": "%s", "default_branch": "%s"}`, name, name)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	var repos []*wardenRepo
+
+	for _, name := range names {
+
+		repo, err := vcsurl.Parse("https://github.com/felicianotech/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		repos = append(repos, WardenRepo(repo, nil))
+	}
+
+	policy := &PolicyFile{DefaultBranch: "trunk"}
+
+	for _, concurrency := range []int{1, 4} {
+
+		results, err := auditRepos(repos, policy, client, concurrency)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results) != len(names) {
+			t.Fatalf("Concurrency %d: Want %d results, got %d", concurrency, len(names), len(results))
+		}
+
+		for i, result := range results {
+			if result.repository != repos[i] || result.rule != RULE_BRANCH_DEFAULT {
+				t.Errorf("Concurrency %d: Want result %d to be the default branch of %s, got %s for %s", concurrency, i+1, names[i], result.rule, result.repository.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// The number of leading values that identify a violation, by rule. This lets
+// a baseline tell two missing labels apart while ignoring values that are
+// expected to change, such as the current permission of a team. Rules not
+// listed are identified by repository and rule alone.
+var baselineKeys = map[string]int{
+	RULE_ACCESS_DIFFERENT: 1,
+	RULE_ACCESS_EXPIRED:   1,
+	RULE_ACCESS_EXPIRING:  1,
+	RULE_ACCESS_EXTRA:     1,
+	RULE_ACCESS_MISSING:   1,
+	RULE_CO_ACCESS:        1,
+	RULE_CO_FORBIDDEN:     2,
+	RULE_CO_OWNERS:        2,
+	RULE_CO_TEAM:          2,
+	RULE_CO_UNKNOWN:       1,
+	RULE_CO_VISIBILITY:    1,
+	RULE_LABEL_EXTRA:      1,
+	RULE_LABEL_MISSING:    1,
+
+	RULE_COLLABORATORS_INVITATION: 1,
+	RULE_COLLABORATORS_OUTSIDE:    1,
+
+	RULE_PROTECTION_CHECKS:     2,
+	RULE_PROTECTION_MISSING:    1,
+	RULE_PROTECTION_SETTING:    2,
+	RULE_PROTECTION_VISIBILITY: 1,
+
+	RULE_RULESET_CHECKS:     2,
+	RULE_RULESET_MISSING:    2,
+	RULE_RULESET_RULE:       2,
+	RULE_RULESET_VISIBILITY: 1,
+	RULE_RULESET_WEAKER:     3,
+
+	RULE_SETTINGS_DIFFERENT:  1,
+	RULE_SETTINGS_VISIBILITY: 1,
+
+	RULE_FILES_FORBIDDEN: 1,
+	RULE_FILES_MISSING:   1,
+
+	RULE_MANAGED_DIFFERENT: 1,
+	RULE_MANAGED_MISSING:   1,
+
+	RULE_ALERTS_OPEN:       2,
+	RULE_ALERTS_VISIBILITY: 1,
+
+	RULE_SECURITY_DISABLED:   1,
+	RULE_SECURITY_VISIBILITY: 1,
+}
+
+// A file recording the violations that existed when it was written
+type baselineFile struct {
+	Violations []baselineEntry `json:"violations"`
+}
+
+// A single recorded violation
+type baselineEntry struct {
+	Repository string `json:"repository"`
+	Rule       string `json:"rule"`
+	Message    string `json:"message"`
+	Values     []any  `json:"values"`
+}
+
+// Returns the key used to match the entry against results
+func (this baselineEntry) key() string {
+	return baselineKey(this.Repository, this.Rule, this.Values)
+}
+
+// Builds a matching key from a repository, rule, and the rule's key values
+func baselineKey(repository, rule string, values []any) string {
+
+	parts := []string{repository, rule}
+
+	for i := 0; i < baselineKeys[rule] && i < len(values); i++ {
+		parts = append(parts, fmt.Sprint(values[i]))
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+// Writes the errors and warnings in results to a baseline file
+func writeBaselineFile(path string, results auditResults) error {
+
+	baseline := baselineFile{Violations: []baselineEntry{}}
+
+	for _, result := range results {
+
+		if result.resultType != RESULT_ERROR && result.resultType != RESULT_WARNING {
+			continue
+		}
+
+		values := result.values
+		if values == nil {
+			values = []any{}
+		}
+
+		baseline.Violations = append(baseline.Violations, baselineEntry{
+			Repository: result.repository.ToHTTPS(),
+			Rule:       result.rule,
+			Message:    result.String(),
+			Values:     values,
+		})
+	}
+
+	content, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0664)
+}
+
+// Loads a baseline file written by writeBaselineFile
+func loadBaselineFile(path string) (*baselineFile, error) {
+
+	var baseline baselineFile
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("The baseline file %s couldn't be read: %s", path, err)
+	}
+
+	err = json.Unmarshal(content, &baseline)
+	if err != nil {
+		return nil, fmt.Errorf("The baseline file %s couldn't be parsed. Something is wrong.", path)
+	}
+
+	return &baseline, nil
+}
+
+// Marks errors and warnings already in the baseline as baselined, so only new
+// violations fail the audit. Violations in the baseline that no longer happen
+// are added as fixed results after the other results of their repository.
+// Only what was audited can be fixed, so entries are skipped for other repos,
+// checks that didn't run, and rules the policy turns off.
+func (this *baselineFile) apply(results auditResults, repos []*wardenRepo, rules map[string]rulePolicy) auditResults {
+
+	known := make(map[string]bool)
+	seen := make(map[string]bool)
+	checked := make(map[string]bool) // by repository and check
+
+	for _, entry := range this.Violations {
+		known[entry.key()] = true
+	}
+
+	for i, result := range results {
+
+		key := baselineKey(result.repository.ToHTTPS(), result.rule, result.values)
+		seen[key] = true
+		checked[result.repository.ToHTTPS()+"\x00"+ruleCheck(result.rule)] = true
+
+		if (result.resultType == RESULT_ERROR || result.resultType == RESULT_WARNING) && known[key] {
+			results[i].resultType = RESULT_BASELINED
+		}
+	}
+
+	fixed := make(map[string]auditResults) // by repository
+
+	for _, entry := range this.Violations {
+
+		if seen[entry.key()] || !checked[entry.Repository+"\x00"+ruleCheck(entry.Rule)] || rules[entry.Rule].Severity == "off" {
+			continue
+		}
+
+		i := slices.IndexFunc(repos, func(repo *wardenRepo) bool {
+			return repo.ToHTTPS() == entry.Repository
+		})
+		if i == -1 {
+			continue
+		}
+
+		// the recorded message is used as is since values decoded from JSON
+		// don't keep their types, numbers coming back as floats
+		message := strings.ReplaceAll(entry.Message, "%", "%%")
+
+		repoFixed := fixed[entry.Repository]
+		repoFixed.add(repos[i], RESULT_FIXED, entry.Rule, message)
+		fixed[entry.Repository] = repoFixed
+
+		// rules that aren't keyed can have several entries, only report them once
+		seen[entry.key()] = true
+	}
+
+	var applied auditResults
+
+	for i, result := range results {
+
+		applied = append(applied, result)
+
+		// a repo's results are together, so its fixed results go after the last
+		if i == len(results)-1 || results[i+1].repository.ToHTTPS() != result.repository.ToHTTPS() {
+			applied = append(applied, fixed[result.repository.ToHTTPS()]...)
+		}
+	}
+
+	return applied
+}
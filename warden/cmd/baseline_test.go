@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/repowarden/cli/warden/vcsurl"
+	"golang.org/x/exp/slices"
+)
+
+func TestBaseline(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	var before auditResults
+	before.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	before.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "high-priority")
+	before.add(repo, RESULT_ERROR, RULE_ACCESS_DIFFERENT, ERR_ACCESS_DIFFERENT, "writers", "push", "admin")
+
+	if err := writeBaselineFile(path, before); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := loadBaselineFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// one label was fixed, one is new, and the team's permission changed again
+	var after auditResults
+	after.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	after.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "wontfix")
+	after.add(repo, RESULT_ERROR, RULE_ACCESS_DIFFERENT, ERR_ACCESS_DIFFERENT, "writers", "push", "maintain")
+
+	after = baseline.apply(after, []*wardenRepo{repo}, nil)
+
+	want := []auditResultType{RESULT_BASELINED, RESULT_ERROR, RESULT_BASELINED, RESULT_FIXED}
+
+	if len(after) != len(want) {
+		t.Fatalf("Want %d results, got %d", len(want), len(after))
+	}
+
+	for i, result := range after {
+		if result.resultType != want[i] {
+			t.Errorf("Result %d: Want %s, got %s", i+1, want[i], result.resultType)
+		}
+	}
+
+	if after[3].String() != "The label 'high-priority' is missing." {
+		t.Errorf("Want the fixed result to use the recorded message, got '%s'", after[3])
+	}
+}
+
+func TestBaselineFixedNumbers(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	var before auditResults
+	before.add(repo, RESULT_ERROR, RULE_COLLABORATORS_INVITATION, ERR_COLLABORATORS_INVITATION, "jdoe", "push", 45)
+	before.add(repo, RESULT_WARNING, RULE_ACCESS_EXPIRING, ERR_ACCESS_EXPIRING, "contractor", "2026-06-20", 10)
+
+	if err := writeBaselineFile(path, before); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := loadBaselineFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both were fixed, so they come back from the baseline file alone
+	var after auditResults
+	after.addPasses(repo, []string{"collaborators", "access"})
+
+	after = baseline.apply(after, []*wardenRepo{repo}, nil)
+
+	if len(after) != 4 {
+		t.Fatalf("Want 2 passes and 2 fixed results, got %d", len(after))
+	}
+
+	for i, result := range after[2:] {
+		if result.resultType != RESULT_FIXED || result.String() != before[i].String() {
+			t.Errorf("Result %d: Want the fixed message '%s', got '%s'", i+1, before[i], result)
+		}
+	}
+}
+
+func TestBaselineExpiringKeys(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	var before auditResults
+	before.add(repo, RESULT_WARNING, RULE_ACCESS_EXPIRING, ERR_ACCESS_EXPIRING, "contractor", "2026-06-20", 10)
+
+	if err := writeBaselineFile(path, before); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := loadBaselineFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a grant to someone else is new, even on the same repo
+	var after auditResults
+	after.add(repo, RESULT_WARNING, RULE_ACCESS_EXPIRING, ERR_ACCESS_EXPIRING, "contractor", "2026-06-20", 5)
+	after.add(repo, RESULT_WARNING, RULE_ACCESS_EXPIRING, ERR_ACCESS_EXPIRING, "responder", "2026-06-12", 2)
+
+	after = baseline.apply(after, []*wardenRepo{repo}, nil)
+
+	if after[0].resultType != RESULT_BASELINED || after[1].resultType != RESULT_WARNING {
+		t.Errorf("Want only the contractor's grant to be baselined, got %s and %s", after[0].resultType, after[1].resultType)
+	}
+}
+
+func TestBaselineAuditedRepos(t *testing.T) {
+
+	var repos []*wardenRepo
+
+	for _, name := range []string{"alpha", "bravo", "charlie"} {
+
+		url, err := vcsurl.Parse("https://github.com/felicianotech/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		repos = append(repos, WardenRepo(url, nil))
+	}
+
+	alpha, bravo, charlie := repos[0], repos[1], repos[2]
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	var before auditResults
+	before.add(alpha, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	before.add(alpha, RESULT_ERROR, RULE_BRANCH_DEFAULT, ERR_BRANCH_DEFAULT, "trunk", "main")
+	before.add(alpha, RESULT_ERROR, RULE_LICENSE_MISSING, ERR_LICENSE_MISSING)
+	before.add(bravo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	before.add(charlie, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+
+	if err := writeBaselineFile(path, before); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := loadBaselineFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bravo isn't audited, the license check didn't run on alpha, and the
+	// branch rule is turned off
+	var after auditResults
+	after.addPasses(alpha, []string{"label", "branch"})
+	after.add(charlie, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+
+	rules := map[string]rulePolicy{RULE_BRANCH_DEFAULT: {Severity: "off"}}
+
+	after = baseline.apply(after, []*wardenRepo{alpha, charlie}, rules)
+
+	var got []string
+	for _, result := range after {
+		got = append(got, result.repository.Name+" "+result.resultType.String()+" "+result.rule)
+	}
+
+	want := []string{
+		"alpha pass label",
+		"alpha pass branch",
+		"alpha fixed " + RULE_LABEL_MISSING,
+		"charlie baselined " + RULE_LABEL_MISSING,
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
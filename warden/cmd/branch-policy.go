@@ -0,0 +1,38 @@
+package cmd
+
+// Checks the repository's default branch
+type branchCheck struct{}
+
+func init() {
+	registerCheck(branchCheck{})
+}
+
+func (this branchCheck) ID() string {
+	return "branch"
+}
+
+func (this branchCheck) Description() string {
+	return "The repository's default branch matches the policy."
+}
+
+func (this branchCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+	return policy.DefaultBranch != ""
+}
+
+func (this branchCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	if snapshot.data.GetDefaultBranch() != policy.DefaultBranch {
+		results.add(
+			snapshot.repo,
+			RESULT_ERROR,
+			RULE_BRANCH_DEFAULT,
+			ERR_BRANCH_DEFAULT,
+			policy.DefaultBranch,
+			snapshot.data.GetDefaultBranch(),
+		)
+	}
+
+	return results, nil
+}
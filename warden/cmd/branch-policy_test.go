@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestBranchCheck(t *testing.T) {
+
+	tcs := []struct {
+		defaultBranch string
+		rules         []string
+	}{
+		{defaultBranch: "trunk", rules: nil},
+		{defaultBranch: "master", rules: []string{RULE_BRANCH_DEFAULT}},
+	}
+
+	policy := &PolicyFile{DefaultBranch: "trunk"}
+
+	for i, tc := range tcs {
+
+		snapshot := testSnapshot(t, nil, &github.Repository{DefaultBranch: github.String(tc.defaultBranch)})
+
+		results, err := branchCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+
+	if (branchCheck{}).Applies(&PolicyFile{}, testSnapshot(t, nil, nil)) {
+		t.Error("The branch check shouldn't apply when the policy has no default branch.")
+	}
+}
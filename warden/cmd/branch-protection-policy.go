@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"path"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// The protection settings branches should have. Settings that are left out
+// aren't checked.
+type branchProtectionPolicy struct {
+	Branches                 []string `yaml:"branches"` // patterns, defaults to the audited branch
+	RequiredApprovingReviews *int     `yaml:"requiredApprovingReviews"`
+	DismissStaleReviews      *bool    `yaml:"dismissStaleReviews"`
+	RequireCodeOwnerReviews  *bool    `yaml:"requireCodeOwnerReviews"`
+	RequiredStatusChecks     []string `yaml:"requiredStatusChecks"`
+	EnforceAdmins            *bool    `yaml:"enforceAdmins"`
+	RequireLinearHistory     *bool    `yaml:"requireLinearHistory"`
+	AllowForcePushes         *bool    `yaml:"allowForcePushes"`
+	AllowDeletions           *bool    `yaml:"allowDeletions"`
+	Tags                     []string `yaml:"tags"`
+}
+
+// Checks the protection of the default branch or the branches the policy names
+type branchProtectionCheck struct{}
+
+func init() {
+	registerCheck(branchProtectionCheck{})
+}
+
+func (this branchProtectionCheck) ID() string {
+	return "protection"
+}
+
+func (this branchProtectionCheck) Description() string {
+	return "The repository's branches are protected the way the policy requires."
+}
+
+func (this branchProtectionCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, bpPolicy := range policy.BranchProtection {
+		if tagsMatched(bpPolicy.Tags, snapshot.repo.Tags()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this branchProtectionCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	for _, bpPolicy := range policy.BranchProtection {
+
+		if !tagsMatched(bpPolicy.Tags, snapshot.repo.Tags()) {
+			continue
+		}
+
+		branches, err := bpPolicy.matchingBranches(snapshot)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, branch := range branches {
+
+			protection, err := snapshot.BranchProtection(branch)
+			if isForbidden(err) || isNotFound(err) {
+
+				// protection can only be read with admin access to the repo
+				results.add(snapshot.repo, RESULT_WARNING, RULE_PROTECTION_VISIBILITY, ERR_PROTECTION_VISIBILITY, branch)
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			results.merge(auditBranchProtection(bpPolicy, snapshot.repo, branch, protection))
+		}
+	}
+
+	return results, nil
+}
+
+// Returns the branches the policy applies to. Without patterns, that's the
+// branch being audited.
+func (this branchProtectionPolicy) matchingBranches(snapshot *repoSnapshot) ([]string, error) {
+
+	if len(this.Branches) == 0 {
+		return []string{snapshot.branch}, nil
+	}
+
+	var matched []string
+
+	branches, err := snapshot.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, branch := range branches {
+		for _, pattern := range this.Branches {
+
+			if ok, _ := path.Match(pattern, branch); ok {
+				matched = append(matched, branch)
+				break
+			}
+		}
+	}
+
+	return matched, nil
+}
+
+// Compares a branch's protection to the policy. A nil protection means the
+// branch isn't protected at all.
+func auditBranchProtection(policy branchProtectionPolicy, repo *wardenRepo, branch string, protection *github.Protection) auditResults {
+
+	var results auditResults
+
+	if protection == nil {
+		results.add(repo, RESULT_ERROR, RULE_PROTECTION_MISSING, ERR_PROTECTION_MISSING, branch)
+		return results
+	}
+
+	reviews := protection.GetRequiredPullRequestReviews()
+	if reviews == nil {
+		reviews = &github.PullRequestReviewsEnforcement{}
+	}
+
+	// GitHub leaves out settings that were never set, which means they're off
+	enforceAdmins := protection.GetEnforceAdmins()
+	if enforceAdmins == nil {
+		enforceAdmins = &github.AdminEnforcement{}
+	}
+
+	linearHistory := protection.GetRequireLinearHistory()
+	if linearHistory == nil {
+		linearHistory = &github.RequireLinearHistory{}
+	}
+
+	forcePushes := protection.GetAllowForcePushes()
+	if forcePushes == nil {
+		forcePushes = &github.AllowForcePushes{}
+	}
+
+	deletions := protection.GetAllowDeletions()
+	if deletions == nil {
+		deletions = &github.AllowDeletions{}
+	}
+
+	compare := func(setting string, want any, have any) {
+		if want != have {
+			results.add(repo, RESULT_ERROR, RULE_PROTECTION_SETTING, ERR_PROTECTION_SETTING, branch, setting, want, have)
+		}
+	}
+
+	if policy.RequiredApprovingReviews != nil && reviews.RequiredApprovingReviewCount < *policy.RequiredApprovingReviews {
+		results.add(repo, RESULT_ERROR, RULE_PROTECTION_SETTING, ERR_PROTECTION_SETTING, branch, "requiredApprovingReviews", *policy.RequiredApprovingReviews, reviews.RequiredApprovingReviewCount)
+	}
+
+	if policy.DismissStaleReviews != nil {
+		compare("dismissStaleReviews", *policy.DismissStaleReviews, reviews.DismissStaleReviews)
+	}
+
+	if policy.RequireCodeOwnerReviews != nil {
+		compare("requireCodeOwnerReviews", *policy.RequireCodeOwnerReviews, reviews.RequireCodeOwnerReviews)
+	}
+
+	if policy.EnforceAdmins != nil {
+		compare("enforceAdmins", *policy.EnforceAdmins, enforceAdmins.Enabled)
+	}
+
+	if policy.RequireLinearHistory != nil {
+		compare("requireLinearHistory", *policy.RequireLinearHistory, linearHistory.Enabled)
+	}
+
+	if policy.AllowForcePushes != nil {
+		compare("allowForcePushes", *policy.AllowForcePushes, forcePushes.Enabled)
+	}
+
+	if policy.AllowDeletions != nil {
+		compare("allowDeletions", *policy.AllowDeletions, deletions.Enabled)
+	}
+
+	var statusChecks []string
+
+	if protection.GetRequiredStatusChecks() != nil {
+
+		statusChecks = append(statusChecks, protection.GetRequiredStatusChecks().Contexts...)
+
+		for _, check := range protection.GetRequiredStatusChecks().Checks {
+			statusChecks = append(statusChecks, check.Context)
+		}
+	}
+
+	for _, check := range policy.RequiredStatusChecks {
+
+		if !slices.Contains(statusChecks, check) {
+			results.add(repo, RESULT_ERROR, RULE_PROTECTION_CHECKS, ERR_PROTECTION_CHECKS, branch, check)
+		}
+	}
+
+	return results
+}
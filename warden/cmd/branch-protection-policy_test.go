@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestAuditBranchProtection(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	policy := branchProtectionPolicy{
+		RequiredApprovingReviews: github.Int(2),
+		RequireCodeOwnerReviews:  github.Bool(true),
+		RequiredStatusChecks:     []string{"test", "lint"},
+		AllowForcePushes:         github.Bool(false),
+	}
+
+	tcs := []struct {
+		protection *github.Protection
+		rules      []string
+	}{
+		{
+			protection: nil,
+			rules:      []string{RULE_PROTECTION_MISSING},
+		},
+		{
+			protection: &github.Protection{
+				RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{RequiredApprovingReviewCount: 3, RequireCodeOwnerReviews: true},
+				RequiredStatusChecks:       &github.RequiredStatusChecks{Contexts: []string{"test", "lint", "build"}},
+				AllowForcePushes:           &github.AllowForcePushes{Enabled: false},
+			},
+			rules: nil,
+		},
+		{
+			protection: &github.Protection{
+				RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{RequiredApprovingReviewCount: 1},
+				RequiredStatusChecks:       &github.RequiredStatusChecks{Checks: []*github.RequiredStatusCheck{{Context: "test"}}},
+				AllowForcePushes:           &github.AllowForcePushes{Enabled: true},
+			},
+			rules: []string{RULE_PROTECTION_SETTING, RULE_PROTECTION_SETTING, RULE_PROTECTION_SETTING, RULE_PROTECTION_CHECKS},
+		},
+	}
+
+	for i, tc := range tcs {
+
+		results := auditBranchProtection(policy, repo, "trunk", tc.protection)
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+}
+
+func TestAuditBranchProtectionMissingSettings(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	policy := branchProtectionPolicy{
+		EnforceAdmins:        github.Bool(true),
+		RequireLinearHistory: github.Bool(true),
+		AllowForcePushes:     github.Bool(false),
+		AllowDeletions:       github.Bool(false),
+	}
+
+	// settings GitHub leaves out are off
+	results := auditBranchProtection(policy, repo, "trunk", &github.Protection{})
+
+	want := []string{RULE_PROTECTION_SETTING, RULE_PROTECTION_SETTING}
+
+	if !slices.Equal(resultRules(results), want) {
+		t.Errorf("Want rules %v, got %v", want, resultRules(results))
+	}
+}
+
+func TestBranchProtectionMatchingBranches(t *testing.T) {
+
+	snapshot := testSnapshot(t, nil, &github.Repository{DefaultBranch: github.String("trunk")})
+	snapshot.loaded["branches"] = true
+	snapshot.branches = []string{"trunk", "release/1.0", "release/2.0", "feature/x"}
+
+	branches, _ := branchProtectionPolicy{}.matchingBranches(snapshot)
+	if !slices.Equal(branches, []string{"trunk"}) {
+		t.Errorf("Want only the audited branch without patterns, got %v", branches)
+	}
+
+	branches, _ = branchProtectionPolicy{Branches: []string{"trunk", "release/*"}}.matchingBranches(snapshot)
+	if !slices.Equal(branches, []string{"trunk", "release/1.0", "release/2.0"}) {
+		t.Errorf("Want the branches matching the patterns, got %v", branches)
+	}
+}
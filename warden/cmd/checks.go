@@ -0,0 +1,721 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// A policy check that can be run against a repository. Checks register
+// themselves with registerCheck and `warden audit` runs every check that
+// applies to a repo.
+type Check interface {
+	// A short, stable identifier. The IDs of the rules a check reports are
+	// prefixed with it, such as 'label' for 'label.missing'.
+	ID() string
+
+	// A one sentence description of what the check verifies
+	Description() string
+
+	// Whether the policy asks for this check to run against the repository,
+	// typically based on the repo's tags
+	Applies(policy *PolicyFile, snapshot *repoSnapshot) bool
+
+	// Runs the check, returning a result for each problem found. An error is
+	// only returned when the check couldn't be completed.
+	Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error)
+}
+
+// the registered checks, in the order they run
+var checks []Check
+
+// Adds a check to the registry. Meant to be called from init().
+func registerCheck(check Check) {
+	checks = append(checks, check)
+}
+
+// What Warden knows about organizations during an audit. Some data, like the
+// teams of an org, is the same for every repo in it, so it's fetched once and
+// shared by the snapshots of all the repos being audited.
+type orgCache struct {
+	mu   sync.Mutex
+	data map[string]map[string]string // by kind and org, such as 'teamParents/felicianotech'
+	errs map[string]error
+}
+
+// Create a new orgCache
+func newOrgCache() *orgCache {
+
+	return &orgCache{
+		data: make(map[string]map[string]string),
+		errs: make(map[string]error),
+	}
+}
+
+// Returns the data of a kind for an org, calling fetch the first time it's
+// asked for. Errors are kept as well, so an org that can't be read isn't
+// asked again. The lock is held while fetching so that concurrent audits of
+// an org wait for the first fetch instead of repeating it.
+func (this *orgCache) load(kind, org string, fetch func() (map[string]string, error)) (map[string]string, error) {
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	key := kind + "/" + strings.ToLower(org)
+
+	if data, ok := this.data[key]; ok {
+		return data, this.errs[key]
+	}
+
+	data, err := fetch()
+
+	this.data[key] = data
+	this.errs[key] = err
+
+	return data, err
+}
+
+// What Warden knows about a repository while it's being audited. Data beyond
+// the repository itself is fetched from GitHub the first time a check asks
+// for it and then reused by the other checks.
+type repoSnapshot struct {
+	repo   *wardenRepo
+	data   *github.Repository
+	branch string // the branch being audited, the default branch unless '--branch' is used
+	client *github.Client
+	orgs   *orgCache
+
+	loaded           map[string]bool
+	labels           []*github.Label
+	teams            []*github.Team
+	teamsErr         error
+	collaborators    map[string][]*github.User // by affiliation
+	collaboratorsErr map[string]error
+	invitations      []*github.RepositoryInvitation
+	customRoles      map[string]string  // base role by custom role name
+	teamParents      map[string]string  // parent slug by team slug
+	files            map[string]*string // nil when the file doesn't exist
+	codeownersErrors *github.CodeownersErrors
+	branches         []string
+	protections      map[string]*github.Protection // nil when the branch isn't protected
+	rules            map[string][]*effectiveRule
+	rulesets         []*ruleset
+	security         map[string]bool
+	alerts           map[string][]*securityAlert
+	orgTeams         map[string]*github.Team // nil when the team doesn't exist
+	userPermissions  map[string]string       // empty when the user doesn't exist
+}
+
+// Create a new repoSnapshot
+func newRepoSnapshot(repo *wardenRepo, data *github.Repository, branch string, client *github.Client, orgs *orgCache) *repoSnapshot {
+
+	return &repoSnapshot{
+		repo:   repo,
+		data:   data,
+		branch: branch,
+		client: client,
+		orgs:   orgs,
+		loaded: make(map[string]bool),
+		files:  make(map[string]*string),
+
+		protections: make(map[string]*github.Protection),
+		rules:       make(map[string][]*effectiveRule),
+		security:    make(map[string]bool),
+		alerts:      make(map[string][]*securityAlert),
+
+		collaborators:    make(map[string][]*github.User),
+		collaboratorsErr: make(map[string]error),
+		orgTeams:         make(map[string]*github.Team),
+		userPermissions:  make(map[string]string),
+	}
+}
+
+// Returns the repository's issue labels
+func (this *repoSnapshot) Labels() ([]*github.Label, error) {
+
+	if !this.loaded["labels"] {
+
+		var labels []*github.Label
+
+		opts := &github.ListOptions{PerPage: 100}
+
+		for {
+			page, resp, err := this.client.Issues.ListLabels(context.Background(), this.repo.Owner, this.repo.Name, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			labels = append(labels, page...)
+
+			if resp.NextPage == 0 {
+				break
+			}
+
+			opts.Page = resp.NextPage
+		}
+
+		this.labels = labels
+		this.loaded["labels"] = true
+	}
+
+	return this.labels, nil
+}
+
+// Returns the teams with access to the repository. This requires admin access
+// to the repo and otherwise returns a not found error.
+func (this *repoSnapshot) Teams() ([]*github.Team, error) {
+
+	if !this.loaded["teams"] {
+
+		var teams []*github.Team
+		var err error
+
+		opts := &github.ListOptions{PerPage: 100}
+
+		for {
+			var page []*github.Team
+			var resp *github.Response
+
+			page, resp, err = this.client.Repositories.ListTeams(context.Background(), this.repo.Owner, this.repo.Name, opts)
+			if err != nil {
+				teams = nil
+				break
+			}
+
+			teams = append(teams, page...)
+
+			if resp.NextPage == 0 {
+				break
+			}
+
+			opts.Page = resp.NextPage
+		}
+
+		this.teams = teams
+		this.teamsErr = err
+		this.loaded["teams"] = true
+	}
+
+	return this.teams, this.teamsErr
+}
+
+// Returns the repository's collaborators by affiliation, 'direct' for users
+// given access directly rather than through a team or 'outside' for those
+// that aren't members of the org. This requires push access to the repo.
+func (this *repoSnapshot) Collaborators(affiliation string) ([]*github.User, error) {
+
+	key := "collaborators/" + affiliation
+
+	if !this.loaded[key] {
+
+		var collaborators []*github.User
+		var err error
+
+		opts := &github.ListCollaboratorsOptions{Affiliation: affiliation, ListOptions: github.ListOptions{PerPage: 100}}
+
+		for {
+			var users []*github.User
+			var resp *github.Response
+
+			users, resp, err = this.client.Repositories.ListCollaborators(context.Background(), this.repo.Owner, this.repo.Name, opts)
+			if err != nil {
+				collaborators = nil
+				break
+			}
+
+			collaborators = append(collaborators, users...)
+
+			if resp.NextPage == 0 {
+				break
+			}
+
+			opts.Page = resp.NextPage
+		}
+
+		this.collaborators[affiliation] = collaborators
+		this.collaboratorsErr[affiliation] = err
+		this.loaded[key] = true
+	}
+
+	return this.collaborators[affiliation], this.collaboratorsErr[affiliation]
+}
+
+// Returns the repository's pending invitations. This requires admin access to
+// the repo.
+func (this *repoSnapshot) Invitations() ([]*github.RepositoryInvitation, error) {
+
+	if !this.loaded["invitations"] {
+
+		opts := &github.ListOptions{PerPage: 100}
+
+		for {
+			invitations, resp, err := this.client.Repositories.ListInvitations(context.Background(), this.repo.Owner, this.repo.Name, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			this.invitations = append(this.invitations, invitations...)
+
+			if resp.NextPage == 0 {
+				break
+			}
+
+			opts.Page = resp.NextPage
+		}
+
+		this.loaded["invitations"] = true
+	}
+
+	return this.invitations, nil
+}
+
+// Returns the base role of each custom repository role in the repo's
+// organization, by the custom role's name. These are shared by every repo in
+// the org.
+func (this *repoSnapshot) CustomRoles() (map[string]string, error) {
+
+	if !this.loaded["customRoles"] {
+
+		customRoles, err := this.orgs.load("customRoles", this.repo.Owner, func() (map[string]string, error) {
+
+			roles, _, err := this.client.Organizations.ListCustomRepoRoles(context.Background(), this.repo.Owner)
+			if err != nil {
+				return nil, err
+			}
+
+			customRoles := make(map[string]string)
+
+			for _, role := range roles.CustomRepoRoles {
+				customRoles[role.GetName()] = role.GetBaseRole()
+			}
+
+			return customRoles, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		this.customRoles = customRoles
+		this.loaded["customRoles"] = true
+	}
+
+	return this.customRoles, nil
+}
+
+// Returns the parent of each team in the repo's organization that has one,
+// by team slug. These are shared by every repo in the org.
+func (this *repoSnapshot) TeamParents() (map[string]string, error) {
+
+	if !this.loaded["teamParents"] {
+
+		parents, err := this.orgs.load("teamParents", this.repo.Owner, func() (map[string]string, error) {
+
+			parents := make(map[string]string)
+			opts := &github.ListOptions{PerPage: 100}
+
+			for {
+				teams, resp, err := this.client.Teams.ListTeams(context.Background(), this.repo.Owner, opts)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, team := range teams {
+					if team.Parent != nil {
+						parents[team.GetSlug()] = team.GetParent().GetSlug()
+					}
+				}
+
+				if resp.NextPage == 0 {
+					break
+				}
+
+				opts.Page = resp.NextPage
+			}
+
+			return parents, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		this.teamParents = parents
+		this.loaded["teamParents"] = true
+	}
+
+	return this.teamParents, nil
+}
+
+// Returns a team of an organization by its slug, or nil if it doesn't exist
+func (this *repoSnapshot) OrgTeam(org, slug string) (*github.Team, error) {
+
+	key := strings.ToLower(org + "/" + slug)
+
+	if team, ok := this.orgTeams[key]; ok {
+		return team, nil
+	}
+
+	team, _, err := this.client.Teams.GetTeamBySlug(context.Background(), org, slug)
+	if isNotFound(err) {
+		team = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	this.orgTeams[key] = team
+
+	return team, nil
+}
+
+// Returns a user's permission on the repository, such as 'write' or 'none',
+// or an empty string if the user doesn't exist
+func (this *repoSnapshot) UserPermission(user string) (string, error) {
+
+	key := strings.ToLower(user)
+
+	if permission, ok := this.userPermissions[key]; ok {
+		return permission, nil
+	}
+
+	level, _, err := this.client.Repositories.GetPermissionLevel(context.Background(), this.repo.Owner, this.repo.Name, user)
+	if isNotFound(err) {
+		level = nil
+	} else if err != nil {
+		return "", err
+	}
+
+	this.userPermissions[key] = level.GetPermission()
+
+	return level.GetPermission(), nil
+}
+
+// Returns the content of a file on the audited branch. found is false when the
+// file doesn't exist.
+func (this *repoSnapshot) File(path string) (content string, found bool, err error) {
+
+	if file, ok := this.files[path]; ok {
+
+		if file == nil {
+			return "", false, nil
+		}
+
+		return *file, true, nil
+	}
+
+	// directories come back as a listing without a file
+	file, _, _, err := this.client.Repositories.GetContents(context.Background(), this.repo.Owner, this.repo.Name, path, &github.RepositoryContentGetOptions{Ref: this.branch})
+	if isNotFound(err) || (err == nil && file == nil) {
+		this.files[path] = nil
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	content, err = file.GetContent()
+	if err != nil {
+		return "", false, err
+	}
+
+	this.files[path] = &content
+
+	return content, true, nil
+}
+
+// Returns the syntax errors GitHub found in the repo's CODEOWNERS file
+func (this *repoSnapshot) CodeownersErrors() (*github.CodeownersErrors, error) {
+
+	if !this.loaded["codeownersErrors"] {
+
+		coErrs, _, err := this.client.Repositories.GetCodeownersErrors(context.Background(), this.repo.Owner, this.repo.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		this.codeownersErrors = coErrs
+		this.loaded["codeownersErrors"] = true
+	}
+
+	return this.codeownersErrors, nil
+}
+
+// Returns the names of every branch in the repository
+func (this *repoSnapshot) Branches() ([]string, error) {
+
+	if !this.loaded["branches"] {
+
+		opts := &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+		for {
+			branches, resp, err := this.client.Repositories.ListBranches(context.Background(), this.repo.Owner, this.repo.Name, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, branch := range branches {
+				this.branches = append(this.branches, branch.GetName())
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+
+			opts.ListOptions.Page = resp.NextPage
+		}
+
+		this.loaded["branches"] = true
+	}
+
+	return this.branches, nil
+}
+
+// Returns the protection of a branch, or nil if it isn't protected
+func (this *repoSnapshot) BranchProtection(branch string) (*github.Protection, error) {
+
+	if protection, ok := this.protections[branch]; ok {
+		return protection, nil
+	}
+
+	protection, _, err := this.client.Repositories.GetBranchProtection(context.Background(), this.repo.Owner, this.repo.Name, branch)
+	if errors.Is(err, github.ErrBranchNotProtected) {
+		protection = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	this.protections[branch] = protection
+
+	return protection, nil
+}
+
+// Returns the rules in effect on a branch from both repository and
+// organization rulesets
+func (this *repoSnapshot) EffectiveRules(branch string) ([]*effectiveRule, error) {
+
+	if rules, ok := this.rules[branch]; ok {
+		return rules, nil
+	}
+
+	var rules []*effectiveRule
+
+	// the GitHub client doesn't support rulesets yet
+	req, err := this.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/rules/branches/%s?per_page=100", this.repo.Owner, this.repo.Name, url.PathEscape(branch)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = this.client.Do(context.Background(), req, &rules)
+	if err != nil {
+		return nil, err
+	}
+
+	this.rules[branch] = rules
+
+	return rules, nil
+}
+
+// Returns the rulesets for the repository, including the organization's
+func (this *repoSnapshot) Rulesets() ([]*ruleset, error) {
+
+	if !this.loaded["rulesets"] {
+
+		req, err := this.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/rulesets?includes_parents=true&per_page=100", this.repo.Owner, this.repo.Name), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = this.client.Do(context.Background(), req, &this.rulesets)
+		if err != nil {
+			return nil, err
+		}
+
+		this.loaded["rulesets"] = true
+	}
+
+	return this.rulesets, nil
+}
+
+// Returned for secret scanning features when GitHub leaves the repository's
+// security and analysis settings out, which it does for non-admins
+var errSecurityNotReturned = errors.New("GitHub didn't return the security and analysis settings.")
+
+// Returned for Dependabot features without admin access to the repository.
+// GitHub answers with a not found both when they're off and when they can't
+// be seen, so the answer can only be trusted with admin access.
+var errSecurityNotAdmin = errors.New("Admin access is needed to read the Dependabot settings.")
+
+// Returns whether a security feature is enabled for the repository. Reading
+// most features requires admin access or a security manager role, and GitHub
+// answers with a forbidden or not found error otherwise.
+func (this *repoSnapshot) SecurityFeature(name string) (bool, error) {
+
+	if enabled, ok := this.security[name]; ok {
+		return enabled, nil
+	}
+
+	if (name == "dependabotAlerts" || name == "dependabotSecurityUpdates") && !this.data.GetPermissions()["admin"] {
+		return false, errSecurityNotAdmin
+	}
+
+	var enabled bool
+	var err error
+
+	switch name {
+	case "dependabotAlerts":
+		enabled, _, err = this.client.Repositories.GetVulnerabilityAlerts(context.Background(), this.repo.Owner, this.repo.Name)
+	case "secretScanning", "secretScanningPushProtection":
+
+		sa := this.data.GetSecurityAndAnalysis()
+		if sa == nil {
+			return false, errSecurityNotReturned
+		}
+
+		if name == "secretScanning" {
+			enabled = sa.GetSecretScanning().GetStatus() == "enabled"
+		} else {
+			enabled = sa.GetSecretScanningPushProtection().GetStatus() == "enabled"
+		}
+	default:
+
+		// the GitHub client doesn't support these endpoints yet
+		paths := map[string]string{
+			"dependabotSecurityUpdates":     "automated-security-fixes",
+			"codeScanningDefaultSetup":      "code-scanning/default-setup",
+			"privateVulnerabilityReporting": "private-vulnerability-reporting",
+		}
+
+		path, ok := paths[name]
+		if !ok {
+			return false, fmt.Errorf("'%s' is not a known security feature.", name)
+		}
+
+		req, err := this.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/%s", this.repo.Owner, this.repo.Name, path), nil)
+		if err != nil {
+			return false, err
+		}
+
+		var status struct {
+			Enabled bool   `json:"enabled"`
+			State   string `json:"state"`
+		}
+
+		_, err = this.client.Do(context.Background(), req, &status)
+
+		// with admin access, GitHub answers with a not found when Dependabot
+		// security updates are off rather than saying they're disabled
+		if isNotFound(err) && name == "dependabotSecurityUpdates" {
+			err = nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		enabled = status.Enabled || status.State == "configured"
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	this.security[name] = enabled
+
+	return enabled, nil
+}
+
+// Returns the open alerts a security tool reported for the repository. The
+// tool is one of 'dependabot', 'codeScanning', and 'secretScanning'.
+func (this *repoSnapshot) Alerts(tool string) ([]*securityAlert, error) {
+
+	if alerts, ok := this.alerts[tool]; ok {
+		return alerts, nil
+	}
+
+	var alerts []*securityAlert
+
+	switch tool {
+	case "dependabot":
+
+		opts := &github.ListAlertsOptions{State: github.String("open"), ListCursorOptions: github.ListCursorOptions{PerPage: 100}}
+
+		for {
+			page, resp, err := this.client.Dependabot.ListRepoAlerts(context.Background(), this.repo.Owner, this.repo.Name, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, alert := range page {
+				alerts = append(alerts, &securityAlert{
+					Severity: alert.GetSecurityAdvisory().GetSeverity(),
+					Created:  alert.GetCreatedAt().Time,
+					URL:      alert.GetHTMLURL(),
+				})
+			}
+
+			// Dependabot alerts are paged with a cursor rather than page numbers
+			if resp.After == "" {
+				break
+			}
+
+			opts.After = resp.After
+		}
+	case "codeScanning":
+
+		opts := &github.AlertListOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+
+		for {
+			page, resp, err := this.client.CodeScanning.ListAlertsForRepo(context.Background(), this.repo.Owner, this.repo.Name, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, alert := range page {
+				alerts = append(alerts, &securityAlert{
+					Severity: codeScanningSeverity(alert.GetRule()),
+					Created:  alert.GetCreatedAt().Time,
+					URL:      alert.GetHTMLURL(),
+				})
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+
+			opts.ListOptions.Page = resp.NextPage
+		}
+	case "secretScanning":
+
+		opts := &github.SecretScanningAlertListOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+
+		for {
+			page, resp, err := this.client.SecretScanning.ListAlertsForRepo(context.Background(), this.repo.Owner, this.repo.Name, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			// leaked secrets don't have a severity and are always critical
+			for _, alert := range page {
+				alerts = append(alerts, &securityAlert{
+					Severity: "critical",
+					Created:  alert.GetCreatedAt().Time,
+					URL:      alert.GetHTMLURL(),
+				})
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+
+			opts.ListOptions.Page = resp.NextPage
+		}
+	default:
+		return nil, fmt.Errorf("'%s' is not a known security tool.", tool)
+	}
+
+	this.alerts[tool] = alerts
+
+	return alerts, nil
+}
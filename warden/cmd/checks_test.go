@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/repowarden/cli/warden/vcsurl"
+)
+
+// Creates a snapshot that never calls GitHub. Tests fill in any data the
+// check under test needs.
+func testSnapshot(t *testing.T, tags []string, data *github.Repository) *repoSnapshot {
+
+	repo, err := vcsurl.Parse("https://github.com/felicianotech/sonar")
+	if err != nil {
+		t.Fatal("The URL should have parsed successfully but it didn't.")
+	}
+
+	if data == nil {
+		data = &github.Repository{}
+	}
+
+	return newRepoSnapshot(WardenRepo(repo, tags), data, data.GetDefaultBranch(), nil, newOrgCache())
+}
+
+// Returns the rule IDs of results, in order
+func resultRules(results auditResults) []string {
+
+	var rules []string
+
+	for _, result := range results {
+		rules = append(rules, result.rule)
+	}
+
+	return rules
+}
+
+func TestRegisteredChecks(t *testing.T) {
+
+	ids := make(map[string]bool)
+
+	for _, check := range checks {
+
+		if ids[check.ID()] {
+			t.Errorf("The check ID '%s' is registered more than once.", check.ID())
+		}
+
+		ids[check.ID()] = true
+	}
+
+	for rule := range ruleMessages {
+		if !ids[ruleCheck(rule)] {
+			t.Errorf("The rule '%s' doesn't belong to a registered check.", rule)
+		}
+	}
+}
+
+// Returns a client that sends every request to the handler
+func testClient(t *testing.T, handler http.HandlerFunc) *github.Client {
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	return client
+}
+
+// Serves a list of named items over several pages, linking each page to the
+// next one like GitHub does
+func pagedHandler(pages int) http.HandlerFunc {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		if page < pages {
+			next := *r.URL
+			query := next.Query()
+			query.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = query.Encode()
+
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.String()))
+		}
+
+		fmt.Fprintf(w, `[{"name": "item-%d", "slug": "item-%d"}]`, page, page)
+	}
+}
+
+func TestSnapshotPagination(t *testing.T) {
+
+	snapshot := testSnapshot(t, nil, nil)
+	snapshot.client = testClient(t, pagedHandler(3))
+
+	labels, err := snapshot.Labels()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(labels) != 3 || labels[2].GetName() != "item-3" {
+		t.Errorf("Want the labels from every page, got %d", len(labels))
+	}
+
+	teams, err := snapshot.Teams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(teams) != 3 || teams[2].GetSlug() != "item-3" {
+		t.Errorf("Want the teams from every page, got %d", len(teams))
+	}
+}
+
+func TestSnapshotOrgCache(t *testing.T) {
+
+	var requests int32
+
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+
+		atomic.AddInt32(&requests, 1)
+
+		switch r.URL.Path {
+		case "/orgs/felicianotech/teams":
+			fmt.Fprint(w, `[{"slug": "backend", "parent": {"slug": "engineering"}}, {"slug": "engineering"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	orgs := newOrgCache()
+
+	// every repo in the org shares the org's teams and custom roles
+	for i := 0; i < 3; i++ {
+
+		snapshot := testSnapshot(t, nil, nil)
+		snapshot.client = client
+		snapshot.orgs = orgs
+
+		parents, err := snapshot.TeamParents()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(parents) != 1 || parents["backend"] != "engineering" {
+			t.Errorf("Want the parent of backend, got %v", parents)
+		}
+
+		if _, err := snapshot.CustomRoles(); !isNotFound(err) {
+			t.Errorf("Want the custom roles to be not found, got %v", err)
+		}
+	}
+
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("Want the org's teams and custom roles to be requested once, got %d requests", requests)
+	}
+}
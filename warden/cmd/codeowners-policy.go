@@ -1,71 +1,125 @@
 package cmd
 
 import (
-	"context"
-	"fmt"
-	"os"
+	"sort"
+	"strings"
 
-	"github.com/google/go-github/v53/github"
+	"github.com/repowarden/cli/warden/codeowners"
+	"golang.org/x/exp/slices"
 )
 
-// What the codeowners file should look like
+// Where Warden creates a CODEOWNERS file when there isn't one
+const CODEOWNERS_PATH = ".github/CODEOWNERS"
+
+// What the codeowners file should look like. Content requires an exact match
+// while the other fields check the file's rules, which lets repos add their
+// own path specific entries.
 type codeownersPolicy struct {
-	Content string   `yaml:"content"`
-	Tags    []string `yaml:"tags"`
+	Content         string              `yaml:"content"`
+	RequiredOwners  map[string][]string `yaml:"requiredOwners"` // owners the rule for a pattern needs to include
+	ForbiddenOwners []string            `yaml:"forbiddenOwners"`
+	TeamsOnly       bool                `yaml:"teamsOnly"` // every owner needs to be a team
+	CatchAll        bool                `yaml:"catchAll"`  // a rule needs to match every path
+	Tags            []string            `yaml:"tags"`
 }
 
-// Does the work to check codeowners policy against a repository and branch
-func auditCodeownersPolicy(policy codeownersPolicy, repo *wardenRepo, client *github.Client, branch string) auditResults {
+// Checks the repository's CODEOWNERS file
+type codeownersCheck struct{}
 
-	var results auditResults
+func init() {
+	registerCheck(codeownersCheck{})
+}
 
-	if !tagsMatched(policy.Tags, repo.Tags()) {
-		return nil
-	}
+func (this codeownersCheck) ID() string {
+	return "codeowners"
+}
 
-	file, _, _, err := client.Repositories.GetContents(context.Background(), repo.Owner, repo.Name, ".github/CODEOWNERS", &github.RepositoryContentGetOptions{Ref: branch})
-	if err != nil {
+func (this codeownersCheck) Description() string {
+	return "The repository's CODEOWNERS file matches the policy and is valid."
+}
 
-		switch err.(type) {
-		case *github.ErrorResponse:
-			if err.(*github.ErrorResponse).Response != nil && err.(*github.ErrorResponse).Response.StatusCode == 404 {
-				results.add(
-					repo,
-					RESULT_ERROR,
-					ERR_CO_MISSING,
-				)
-
-				return results
-			} else {
-				fmt.Fprintf(os.Stderr, err.Error())
-				return nil
-			}
-		default:
-			fmt.Fprintf(os.Stderr, err.Error())
-			return nil
+func (this codeownersCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, coPolicy := range policy.Codeowners {
+		if tagsMatched(coPolicy.Tags, snapshot.repo.Tags()) {
+			return true
 		}
 	}
 
-	content, err := file.GetContent()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, err.Error())
-		return nil
+	return false
+}
+
+func (this codeownersCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	repo := snapshot.repo
+
+	var paths []string
+	var content string
+
+	for _, path := range codeowners.Paths {
+
+		pathContent, found, err := snapshot.File(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			continue
+		}
+
+		// GitHub only uses the first file it finds
+		if len(paths) == 0 {
+			content = pathContent
+		}
+
+		paths = append(paths, path)
 	}
 
-	// check if the files match
-	if policy.Content != content {
+	if len(paths) == 0 {
 		results.add(
 			repo,
 			RESULT_ERROR,
-			ERR_CO_DIFFERENT,
+			RULE_CO_MISSING,
+			ERR_CO_MISSING,
+		)
+
+		return results, nil
+	}
+
+	if len(paths) > 1 {
+		results.add(
+			repo,
+			RESULT_WARNING,
+			RULE_CO_MULTIPLE,
+			ERR_CO_MULTIPLE,
+			strings.Join(paths, "', '"),
+			paths[0],
 		)
 	}
 
+	// the syntax is reported by GitHub below, so rules that don't parse are
+	// just left out here
+	file, _ := codeowners.Parse(content)
+
+	for _, coPolicy := range policy.Codeowners {
+		results.merge(auditCodeownersPolicy(coPolicy, repo, paths[0], content, file))
+	}
+
+	// the owners and syntax are about the file itself, so they're only
+	// checked once no matter how many policies apply
+	ownerResults, err := auditCodeownersOwners(snapshot, file)
+	if err != nil {
+		return nil, err
+	}
+
+	results.merge(ownerResults)
+
 	// check for codeowners syntax errors
-	coErrs, _, err := client.Repositories.GetCodeownersErrors(context.Background(), repo.Owner, repo.Name)
+	coErrs, err := snapshot.CodeownersErrors()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, err.Error())
-		return nil
+		return nil, err
 	}
 
 	if len(coErrs.Errors) > 0 {
@@ -78,10 +132,158 @@ func auditCodeownersPolicy(policy codeownersPolicy, repo *wardenRepo, client *gi
 		results.add(
 			repo,
 			RESULT_ERROR,
+			RULE_CO_SYNTAX,
 			ERR_CO_SYNTAX,
 			suggestions,
 		)
 	}
 
+	return results, nil
+}
+
+// Does the work to check a codeowners policy against the CODEOWNERS file
+// GitHub uses, found at path
+func auditCodeownersPolicy(policy codeownersPolicy, repo *wardenRepo, path, content string, file *codeowners.File) auditResults {
+
+	var results auditResults
+
+	if !tagsMatched(policy.Tags, repo.Tags()) {
+		return nil
+	}
+
+	// check if the files match
+	if policy.Content != "" && policy.Content != content {
+		results.add(
+			repo,
+			RESULT_ERROR,
+			RULE_CO_DIFFERENT,
+			ERR_CO_DIFFERENT,
+			path,
+		)
+	}
+
+	results.merge(auditCodeownersRules(policy, repo, file))
+
 	return results
 }
+
+// Checks the rules of a CODEOWNERS file against the semantic parts of the
+// policy
+func auditCodeownersRules(policy codeownersPolicy, repo *wardenRepo, file *codeowners.File) auditResults {
+
+	var results auditResults
+
+	if policy.CatchAll && !file.HasCatchAll() {
+		results.add(repo, RESULT_ERROR, RULE_CO_CATCHALL, ERR_CO_CATCHALL)
+	}
+
+	var patterns []string
+	for pattern := range policy.RequiredOwners {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+
+		// the last rule for a pattern is the one that takes effect
+		var owners []string
+		if rules := file.RulesFor(pattern); len(rules) > 0 {
+			owners = rules[len(rules)-1].Owners
+		}
+
+		for _, owner := range policy.RequiredOwners[pattern] {
+			if !slices.Contains(owners, owner) {
+				results.add(repo, RESULT_ERROR, RULE_CO_OWNERS, ERR_CO_OWNERS, pattern, owner)
+			}
+		}
+	}
+
+	for _, rule := range file.Rules {
+		for _, owner := range rule.Owners {
+
+			if slices.Contains(policy.ForbiddenOwners, owner) {
+				results.add(repo, RESULT_ERROR, RULE_CO_FORBIDDEN, ERR_CO_FORBIDDEN, owner, rule.Pattern)
+			} else if policy.TeamsOnly && !codeowners.IsTeam(owner) {
+				results.add(repo, RESULT_ERROR, RULE_CO_TEAM, ERR_CO_TEAM, owner, rule.Pattern)
+			}
+		}
+	}
+
+	return results
+}
+
+// Checks that every owner in a CODEOWNERS file exists and can push to the
+// repository. GitHub silently skips review requests for owners that can't.
+func auditCodeownersOwners(snapshot *repoSnapshot, file *codeowners.File) (auditResults, error) {
+
+	var results auditResults
+	var owners []string
+
+	for _, rule := range file.Rules {
+		for _, owner := range rule.Owners {
+			if !slices.Contains(owners, owner) {
+				owners = append(owners, owner)
+			}
+		}
+	}
+
+	for _, owner := range owners {
+
+		var permission string
+		var err error
+
+		switch {
+		case codeowners.IsTeam(owner):
+			permission, err = codeownersTeamPermission(snapshot, owner)
+		case codeowners.IsUser(owner):
+			permission, err = snapshot.UserPermission(strings.TrimPrefix(owner, "@"))
+		default:
+			// emails can't be resolved
+			continue
+		}
+
+		if isForbidden(err) || isNotFound(err) {
+			results.add(snapshot.repo, RESULT_WARNING, RULE_CO_VISIBILITY, ERR_CO_VISIBILITY, owner)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch permission {
+		case "":
+			results.add(snapshot.repo, RESULT_ERROR, RULE_CO_UNKNOWN, ERR_CO_UNKNOWN, owner)
+		case "push", "maintain", "admin", "write":
+		default:
+			results.add(snapshot.repo, RESULT_ERROR, RULE_CO_ACCESS, ERR_CO_ACCESS, owner, permission)
+		}
+	}
+
+	return results, nil
+}
+
+// Returns a team's permission on the repository, 'none' when the team has no
+// access, or an empty string when the team doesn't exist
+func codeownersTeamPermission(snapshot *repoSnapshot, owner string) (string, error) {
+
+	org, slug, _ := strings.Cut(strings.TrimPrefix(owner, "@"), "/")
+
+	teams, err := snapshot.Teams()
+	if err != nil {
+		return "", err
+	}
+
+	if strings.EqualFold(org, snapshot.repo.Owner) {
+		for _, team := range teams {
+			if strings.EqualFold(team.GetSlug(), slug) {
+				return team.GetPermission(), nil
+			}
+		}
+	}
+
+	team, err := snapshot.OrgTeam(org, slug)
+	if err != nil || team == nil {
+		return "", err
+	}
+
+	return "none", nil
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/repowarden/cli/warden/codeowners"
+	"golang.org/x/exp/slices"
+)
+
+// Sets up the owners used by these tests so that nothing is fetched
+func preloadCodeownersOwners(snapshot *repoSnapshot) {
+
+	snapshot.loaded["teams"] = true
+	snapshot.teams = []*github.Team{
+		{Slug: github.String("writers"), Permission: github.String("push")},
+		{Slug: github.String("readers"), Permission: github.String("pull")},
+	}
+
+	snapshot.orgTeams["felicianotech/platform"] = &github.Team{Slug: github.String("platform")}
+	snapshot.orgTeams["felicianotech/ghosts"] = nil
+
+	snapshot.userPermissions["felicianotech"] = "admin"
+	snapshot.userPermissions["octocat"] = "read"
+	snapshot.userPermissions["nobody"] = ""
+}
+
+func TestCodeownersCheck(t *testing.T) {
+
+	policyContent := "*\t@felicianotech/writers\n"
+
+	tcs := []struct {
+		content *string
+		errors  []*github.CodeownersError
+		rules   []string
+	}{
+		{content: github.String(policyContent), rules: nil},
+		{content: nil, rules: []string{RULE_CO_MISSING}},
+		{content: github.String("* @felicianotech\n"), rules: []string{RULE_CO_DIFFERENT}},
+		{
+			content: github.String(policyContent),
+			errors:  []*github.CodeownersError{{Suggestion: github.String("Check the team name")}},
+			rules:   []string{RULE_CO_SYNTAX},
+		},
+	}
+
+	policy := &PolicyFile{Codeowners: []codeownersPolicy{{Content: policyContent}}}
+
+	for i, tc := range tcs {
+
+		snapshot := testSnapshot(t, nil, nil)
+		snapshot.files["CODEOWNERS"] = nil
+		snapshot.files["docs/CODEOWNERS"] = nil
+		preloadCodeownersOwners(snapshot)
+		snapshot.files[CODEOWNERS_PATH] = tc.content
+		snapshot.loaded["codeownersErrors"] = true
+		snapshot.codeownersErrors = &github.CodeownersErrors{Errors: tc.errors}
+
+		results, err := codeownersCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+}
+
+func TestCodeownersLocations(t *testing.T) {
+
+	policy := &PolicyFile{Codeowners: []codeownersPolicy{{Content: "* @felicianotech/writers\n"}}}
+
+	snapshot := testSnapshot(t, nil, nil)
+	snapshot.files[CODEOWNERS_PATH] = nil
+	snapshot.files["CODEOWNERS"] = github.String("* @felicianotech/writers\n")
+	snapshot.files["docs/CODEOWNERS"] = github.String("* @felicianotech\n")
+	preloadCodeownersOwners(snapshot)
+	snapshot.loaded["codeownersErrors"] = true
+	snapshot.codeownersErrors = &github.CodeownersErrors{}
+
+	results, err := codeownersCheck{}.Evaluate(policy, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the root file is used since there isn't one in .github/
+	if !slices.Equal(resultRules(results), []string{RULE_CO_MULTIPLE}) {
+		t.Errorf("Want only a warning about multiple files, got %v", resultRules(results))
+	}
+}
+
+func TestCodeownersCheckPolicies(t *testing.T) {
+
+	policy := &PolicyFile{Codeowners: []codeownersPolicy{
+		{Content: "* @felicianotech/writers\n"},
+		{CatchAll: true},
+	}}
+
+	snapshot := testSnapshot(t, nil, nil)
+	snapshot.files[CODEOWNERS_PATH] = github.String("/docs/ @felicianotech/readers @octocat\n")
+	snapshot.files["CODEOWNERS"] = github.String("* @felicianotech/writers\n")
+	snapshot.files["docs/CODEOWNERS"] = nil
+	preloadCodeownersOwners(snapshot)
+	snapshot.loaded["codeownersErrors"] = true
+	snapshot.codeownersErrors = &github.CodeownersErrors{Errors: []*github.CodeownersError{{Suggestion: github.String("Check the team name")}}}
+
+	results, err := codeownersCheck{}.Evaluate(policy, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the policy checks are repeated for each policy
+	want := []string{RULE_CO_MULTIPLE, RULE_CO_DIFFERENT, RULE_CO_CATCHALL, RULE_CO_ACCESS, RULE_CO_ACCESS, RULE_CO_SYNTAX}
+
+	if !slices.Equal(resultRules(results), want) {
+		t.Errorf("Want rules %v, got %v", want, resultRules(results))
+	}
+}
+
+func TestCodeownersCheckError(t *testing.T) {
+
+	policy := &PolicyFile{Codeowners: []codeownersPolicy{{Content: "* @felicianotech/writers\n"}}}
+
+	snapshot := testSnapshot(t, nil, nil)
+	snapshot.client = testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	// the check couldn't run, so it shouldn't pass either
+	if _, err := (codeownersCheck{}).Evaluate(policy, snapshot); err == nil {
+		t.Error("Want the error from GitHub to be returned.")
+	}
+}
+
+func TestAuditCodeownersRules(t *testing.T) {
+
+	file, err := codeowners.Parse(`*          @felicianotech/platform
+/docs/     @felicianotech/writers @octocat
+/scripts/  @felicianotech/platform @jdoe
+*          @felicianotech/platform @felicianotech/security
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		policy codeownersPolicy
+		rules  []string
+	}{
+		{
+			policy: codeownersPolicy{CatchAll: true, RequiredOwners: map[string][]string{"*": {"@felicianotech/platform", "@felicianotech/security"}}},
+			rules:  nil,
+		},
+		{
+			policy: codeownersPolicy{RequiredOwners: map[string][]string{"/docs/": {"@felicianotech/platform"}, "/src/": {"@felicianotech/platform"}}},
+			rules:  []string{RULE_CO_OWNERS, RULE_CO_OWNERS},
+		},
+		{
+			policy: codeownersPolicy{ForbiddenOwners: []string{"@jdoe"}, TeamsOnly: true},
+			rules:  []string{RULE_CO_TEAM, RULE_CO_FORBIDDEN},
+		},
+	}
+
+	for i, tc := range tcs {
+
+		results := auditCodeownersRules(tc.policy, testSnapshot(t, nil, nil).repo, file)
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+
+	empty, _ := codeowners.Parse("/docs/ @felicianotech/writers\n")
+
+	results := auditCodeownersRules(codeownersPolicy{CatchAll: true}, testSnapshot(t, nil, nil).repo, empty)
+	if !slices.Equal(resultRules(results), []string{RULE_CO_CATCHALL}) {
+		t.Errorf("Want a missing catch-all rule, got %v", resultRules(results))
+	}
+}
+
+func TestAuditCodeownersOwners(t *testing.T) {
+
+	file, _ := codeowners.Parse(`*        @felicianotech/writers @felicianotech
+/docs/   @felicianotech/Readers @octocat docs@example.com
+/infra/  @felicianotech/platform @felicianotech/ghosts @nobody
+/site/   @felicianotech/writers
+`)
+
+	snapshot := testSnapshot(t, nil, nil)
+	preloadCodeownersOwners(snapshot)
+
+	results, err := auditCodeownersOwners(snapshot, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{RULE_CO_ACCESS, RULE_CO_ACCESS, RULE_CO_ACCESS, RULE_CO_UNKNOWN, RULE_CO_UNKNOWN}
+	if !slices.Equal(resultRules(results), want) {
+		t.Errorf("Want rules %v, got %v", want, resultRules(results))
+	}
+
+	// a team that exists in the org but has no access
+	if results[2].values[0] != "@felicianotech/platform" || results[2].values[1] != "none" {
+		t.Errorf("Want the platform team to have no access, got %v", results[2].values)
+	}
+}
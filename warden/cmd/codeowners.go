@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	codeownersCmd = &cobra.Command{
+		Use:   "codeowners",
+		Short: "Subcommands for CODEOWNERS files",
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(codeownersCmd)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/repowarden/cli/warden/codeowners"
+	"github.com/spf13/cobra"
+)
+
+var codeownersTagsFl []string
+
+var (
+	codeownersCheckCmd = &cobra.Command{
+		Use:   "check [path]",
+		Short: "Checks a local CODEOWNERS file without calling GitHub",
+		Long: `Checks a local CODEOWNERS file for parse errors, syntax GitHub doesn't support,
+duplicate patterns, and rules shadowed by later rules. With '--tags', the file
+is also checked against the codeowners policies for those tags.
+
+Without a path, the CODEOWNERS file GitHub would use in the current directory is
+checked.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			var path string
+
+			if len(args) > 0 {
+				path = args[0]
+			} else {
+				for _, candidate := range codeowners.Paths {
+					if _, err := os.Stat(candidate); err == nil {
+						path = candidate
+						break
+					}
+				}
+
+				if path == "" {
+					return errors.New("A CODEOWNERS file wasn't found in .github/, the current directory, or docs/.")
+				}
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			var policy *PolicyFile
+
+			if len(codeownersTagsFl) > 0 {
+
+				policy, _, err = loadPolicyFile(policyFileFl)
+				if err != nil {
+					return err
+				}
+			}
+
+			problems := checkCodeownersFile(string(content), policy, codeownersTagsFl)
+
+			for _, problem := range problems {
+				fmt.Printf("%s: %s\n", path, problem)
+			}
+
+			if len(problems) > 0 {
+				return fmt.Errorf("Found %d problem(s) with %s.", len(problems), path)
+			}
+
+			fmt.Println("The CODEOWNERS file looks good.")
+
+			return nil
+		},
+	}
+)
+
+func init() {
+
+	codeownersCheckCmd.Flags().StringSliceVar(&codeownersTagsFl, "tags", nil, "the repository's tags, to also check the file against matching codeowners policies")
+	AddPolicyFileFlag(codeownersCheckCmd)
+
+	codeownersCmd.AddCommand(codeownersCheckCmd)
+}
+
+// Returns every problem with a CODEOWNERS file. When a policy is given, the
+// file is also compared to the codeowners policies matching the tags.
+func checkCodeownersFile(content string, policy *PolicyFile, tags []string) []string {
+
+	var problems []string
+
+	file, err := codeowners.Parse(content)
+
+	var parseErrs codeowners.ParseErrors
+	if errors.As(err, &parseErrs) {
+		for _, parseErr := range parseErrs {
+			problems = append(problems, parseErr.Error())
+		}
+	}
+
+	for _, problem := range codeowners.Lint(file) {
+		problems = append(problems, problem.String())
+	}
+
+	if policy == nil {
+		return problems
+	}
+
+	for _, coPolicy := range policy.Codeowners {
+
+		if !tagsMatched(coPolicy.Tags, tags) {
+			continue
+		}
+
+		if coPolicy.Content != "" && coPolicy.Content != content {
+			problems = append(problems, "the content is different from the policy")
+		}
+
+		for _, result := range auditCodeownersRules(coPolicy, nil, file) {
+			problems = append(problems, result.String())
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import "testing"
+
+func TestCheckCodeownersFile(t *testing.T) {
+
+	content := `*          @felicianotech/platform
+/docs/     @felicianotech/writers docs
+/src/      @felicianotech/dev
+/src/      @jdoe
+`
+
+	problems := checkCodeownersFile(content, nil, nil)
+	if len(problems) != 2 {
+		t.Errorf("Want a parse error and a repeated pattern, got %v", problems)
+	}
+
+	policy := &PolicyFile{Codeowners: []codeownersPolicy{
+		{TeamsOnly: true, Tags: []string{"go"}},
+		{CatchAll: true, RequiredOwners: map[string][]string{"*": {"@felicianotech/security"}}, Tags: []string{"docs"}},
+	}}
+
+	problems = checkCodeownersFile(content, policy, []string{"go"})
+	if len(problems) != 3 {
+		t.Errorf("Want the file problems and a user owner, got %v", problems)
+	}
+}
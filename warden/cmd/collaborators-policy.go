@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+// Rules for who outside the organization can access a repository. Every
+// direct collaborator is reported along with whether they're an org member
+// and their permission.
+type collaboratorsPolicy struct {
+	ForbidOutside    bool     `yaml:"forbidOutside"`    // outside collaborators aren't allowed
+	AllowOutside     []string `yaml:"allowOutside"`     // outside collaborators that are allowed, implies forbidOutside for the rest
+	MaxInvitationAge *int     `yaml:"maxInvitationAge"` // the days an invitation can stay pending, unchecked when left out
+	Tags             []string `yaml:"tags"`
+}
+
+// Checks the repository's direct and outside collaborators and its pending
+// invitations
+type collaboratorsCheck struct{}
+
+func init() {
+	registerCheck(collaboratorsCheck{})
+}
+
+func (this collaboratorsCheck) ID() string {
+	return "collaborators"
+}
+
+func (this collaboratorsCheck) Description() string {
+	return "The repository only has the outside collaborators and pending invitations the policy allows."
+}
+
+func (this collaboratorsCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, cPolicy := range policy.Collaborators {
+		if tagsMatched(cPolicy.Tags, snapshot.repo.Tags()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this collaboratorsCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	direct, err := snapshot.Collaborators("direct")
+	if isNotFound(err) || isForbidden(err) {
+		results.add(snapshot.repo, RESULT_WARNING, RULE_COLLABORATORS_VISIBILITY, ERR_COLLABORATORS_VISIBILITY)
+		return results, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	outside, err := snapshot.Collaborators("outside")
+	if isNotFound(err) || isForbidden(err) {
+		results.add(snapshot.repo, RESULT_WARNING, RULE_COLLABORATORS_VISIBILITY, ERR_COLLABORATORS_VISIBILITY)
+		return results, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// the policies matching the repo are combined so that an allow list can
+	// make exceptions to a policy forbidding outside collaborators
+	cPolicy := mergeCollaboratorsPolicies(policy, snapshot.repo)
+
+	var invitations []*github.RepositoryInvitation
+
+	if cPolicy.MaxInvitationAge != nil {
+
+		invitations, err = snapshot.Invitations()
+		if isNotFound(err) || isForbidden(err) {
+			results.add(snapshot.repo, RESULT_WARNING, RULE_COLLABORATORS_VISIBILITY, ERR_COLLABORATORS_VISIBILITY)
+			cPolicy.MaxInvitationAge = nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	results.merge(reportCollaborators(snapshot.repo, direct, outside))
+	results.merge(auditCollaboratorsPolicy(cPolicy, snapshot.repo, outside, invitations, time.Now()))
+
+	return results, nil
+}
+
+// Combines the collaborators policies that match the repository. Outside
+// collaborators are forbidden when any policy forbids them, except for the
+// ones any policy allows, and the shortest invitation age is used.
+func mergeCollaboratorsPolicies(policy *PolicyFile, repo *wardenRepo) collaboratorsPolicy {
+
+	var merged collaboratorsPolicy
+
+	for _, cPolicy := range policy.Collaborators {
+
+		if !tagsMatched(cPolicy.Tags, repo.Tags()) {
+			continue
+		}
+
+		merged.ForbidOutside = merged.ForbidOutside || cPolicy.ForbidOutside || len(cPolicy.AllowOutside) > 0
+		merged.AllowOutside = append(merged.AllowOutside, cPolicy.AllowOutside...)
+
+		if cPolicy.MaxInvitationAge != nil && (merged.MaxInvitationAge == nil || *cPolicy.MaxInvitationAge < *merged.MaxInvitationAge) {
+			merged.MaxInvitationAge = cPolicy.MaxInvitationAge
+		}
+	}
+
+	return merged
+}
+
+// Lists each direct collaborator as an org member or outside collaborator,
+// with their permission. Outside collaborators only given access through a
+// team aren't direct, so they're added from the outside list.
+func reportCollaborators(repo *wardenRepo, direct, outside []*github.User) auditResults {
+
+	var results auditResults
+	var logins []string
+
+	isOutside := func(login string) bool {
+		return slices.ContainsFunc(outside, func(user *github.User) bool {
+			return strings.EqualFold(user.GetLogin(), login)
+		})
+	}
+
+	for _, user := range append(append([]*github.User{}, direct...), outside...) {
+
+		if slices.Contains(logins, strings.ToLower(user.GetLogin())) {
+			continue
+		}
+
+		logins = append(logins, strings.ToLower(user.GetLogin()))
+
+		kind := "org member"
+		if isOutside(user.GetLogin()) {
+			kind = "outside collaborator"
+		}
+
+		results.add(repo, RESULT_INFO, RULE_COLLABORATORS_ACCESS, ERR_COLLABORATORS_ACCESS, user.GetLogin(), kind, policyPermission(user.GetRoleName()))
+	}
+
+	return results
+}
+
+// Does the work to check a collaborators policy against a repository
+func auditCollaboratorsPolicy(policy collaboratorsPolicy, repo *wardenRepo, outside []*github.User, invitations []*github.RepositoryInvitation, now time.Time) auditResults {
+
+	var results auditResults
+
+	if policy.ForbidOutside || len(policy.AllowOutside) > 0 {
+
+		for _, user := range outside {
+
+			allowed := slices.ContainsFunc(policy.AllowOutside, func(login string) bool {
+				return strings.EqualFold(login, user.GetLogin())
+			})
+
+			if !allowed {
+				results.add(repo, RESULT_ERROR, RULE_COLLABORATORS_OUTSIDE, ERR_COLLABORATORS_OUTSIDE, user.GetLogin(), policyPermission(user.GetRoleName()))
+			}
+		}
+	}
+
+	if policy.MaxInvitationAge != nil {
+
+		for _, invitation := range invitations {
+
+			age := int(now.Sub(invitation.GetCreatedAt().Time).Hours() / 24)
+
+			if age > *policy.MaxInvitationAge {
+				results.add(repo, RESULT_ERROR, RULE_COLLABORATORS_INVITATION, ERR_COLLABORATORS_INVITATION, invitation.GetInvitee().GetLogin(), policyPermission(invitation.GetPermissions()), age)
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestAuditCollaboratorsPolicy(t *testing.T) {
+
+	now := time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+	repo := testSnapshot(t, nil, nil).repo
+
+	outside := []*github.User{
+		{Login: github.String("contractor"), RoleName: github.String("write")},
+		{Login: github.String("auditor"), RoleName: github.String("read")},
+	}
+
+	invitations := []*github.RepositoryInvitation{
+		{Invitee: &github.User{Login: github.String("new-hire")}, Permissions: github.String("write"), CreatedAt: &github.Timestamp{Time: now.AddDate(0, 0, -3)}},
+		{Invitee: &github.User{Login: github.String("old-hire")}, Permissions: github.String("admin"), CreatedAt: &github.Timestamp{Time: now.AddDate(0, 0, -45)}},
+	}
+
+	tcs := []struct {
+		policy collaboratorsPolicy
+		rules  []string
+	}{
+		{policy: collaboratorsPolicy{}, rules: nil},
+		{policy: collaboratorsPolicy{ForbidOutside: true}, rules: []string{RULE_COLLABORATORS_OUTSIDE, RULE_COLLABORATORS_OUTSIDE}},
+		{policy: collaboratorsPolicy{AllowOutside: []string{"Auditor"}}, rules: []string{RULE_COLLABORATORS_OUTSIDE}},
+		{policy: collaboratorsPolicy{MaxInvitationAge: github.Int(30)}, rules: []string{RULE_COLLABORATORS_INVITATION}},
+		{policy: collaboratorsPolicy{MaxInvitationAge: github.Int(0)}, rules: []string{RULE_COLLABORATORS_INVITATION, RULE_COLLABORATORS_INVITATION}},
+	}
+
+	for i, tc := range tcs {
+
+		results := auditCollaboratorsPolicy(tc.policy, repo, outside, invitations, now)
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+}
+
+func TestReportCollaborators(t *testing.T) {
+
+	direct := []*github.User{
+		{Login: github.String("felicianotech"), RoleName: github.String("admin")},
+		{Login: github.String("contractor"), RoleName: github.String("write")},
+	}
+
+	outside := []*github.User{
+		{Login: github.String("contractor"), RoleName: github.String("write")},
+		{Login: github.String("auditor"), RoleName: github.String("read")},
+	}
+
+	results := reportCollaborators(testSnapshot(t, nil, nil).repo, direct, outside)
+
+	want := []string{
+		"The user 'felicianotech' is an org member with the permission 'admin'.",
+		"The user 'contractor' is an outside collaborator with the permission 'push'.",
+		"The user 'auditor' is an outside collaborator with the permission 'read'.",
+	}
+
+	var got []string
+	for _, result := range results {
+		got = append(got, result.String())
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
+
+func TestCollaboratorsCheck(t *testing.T) {
+
+	// the policies from example.policy.yml
+	policy := &PolicyFile{Collaborators: []collaboratorsPolicy{
+		{ForbidOutside: true, MaxInvitationAge: github.Int(14)},
+		{AllowOutside: []string{"hugo-contractor"}, Tags: []string{"hugo"}},
+	}}
+
+	tcs := []struct {
+		tags    []string
+		outside []string // the outside collaborators reported
+	}{
+		{tags: []string{"hugo"}, outside: []string{"contractor"}},
+		{tags: nil, outside: []string{"contractor", "hugo-contractor"}},
+	}
+
+	for i, tc := range tcs {
+
+		snapshot := testSnapshot(t, tc.tags, nil)
+		snapshot.loaded["collaborators/direct"] = true
+		snapshot.loaded["collaborators/outside"] = true
+		snapshot.loaded["invitations"] = true
+		snapshot.collaborators["direct"] = []*github.User{{Login: github.String("felicianotech"), RoleName: github.String("admin")}}
+		snapshot.collaborators["outside"] = []*github.User{
+			{Login: github.String("contractor"), RoleName: github.String("write")},
+			{Login: github.String("hugo-contractor"), RoleName: github.String("read")},
+		}
+
+		results, err := collaboratorsCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var outside []string
+		for _, result := range results.ByType(RESULT_ERROR) {
+			outside = append(outside, result.values[0].(string))
+		}
+
+		if !slices.Equal(outside, tc.outside) {
+			t.Errorf("Case %d: Want the outside collaborators %v reported, got %v", i+1, tc.outside, outside)
+		}
+
+		// listing the collaborators doesn't fail the check
+		if listed := results.ByType(RESULT_INFO); len(listed) != 3 {
+			t.Errorf("Case %d: Want 3 collaborators listed, got %d", i+1, len(listed))
+		}
+	}
+
+	// the shortest invitation age is used
+	merged := mergeCollaboratorsPolicies(&PolicyFile{Collaborators: []collaboratorsPolicy{
+		{MaxInvitationAge: github.Int(30)},
+		{MaxInvitationAge: github.Int(7)},
+		{},
+	}}, testSnapshot(t, nil, nil).repo)
+
+	if merged.ForbidOutside || merged.MaxInvitationAge == nil || *merged.MaxInvitationAge != 7 {
+		t.Errorf("Want outside collaborators allowed and an invitation age of 7, got %+v", merged)
+	}
+}
@@ -1,16 +1,194 @@
 package cmd
 
+import "strings"
+
 const (
+	ERR_ACCESS_EXPIRED    = "The access for '%s' expired on %s and should be removed."
+	ERR_ACCESS_EXPIRING   = "The access for '%s' expires on %s, %d days from now."
 	ERR_ACCESS_EXTRA      = "The user/team '%s' is present and shouldn't be."
+	ERR_ACCESS_INHERITED  = "The team '%s' has the permission '%s' inherited through %s."
 	ERR_ACCESS_MISSING    = "The user/team %s is not defined."
 	ERR_ACCESS_DIFFERENT  = "The user/team '%s' should have the permission '%s', not '%s'."
 	ERR_ACCESS_STRATEGY   = "'%s' is not a valid access strategy."
+	ERR_ACCESS_VISIBILITY = "Couldn't pull teams or collaborators. There's a visibility issue here."
 	ERR_BRANCH_DEFAULT    = "The default branch should be '%s', not '%s'."
 	ERR_LABEL_EXTRA       = "The label '%s' is present and shouldn't be."
 	ERR_LABEL_MISSING     = "The label '%s' is missing."
 	ERR_LICENSE_DIFFERENT = "The license should be one of '%s', not '%s'."
 	ERR_LICENSE_MISSING   = "The license is missing."
-	ERR_CO_DIFFERENT      = "The CODEOWNERS file is different from the policy."
+	ERR_CO_ACCESS         = "The owner '%s' needs at least push permission to be requested for reviews, not '%s'."
+	ERR_CO_CATCHALL       = "The CODEOWNERS file should have a rule that matches every path, such as '*'."
+	ERR_CO_DIFFERENT      = "The CODEOWNERS file '%s' is different from the policy."
+	ERR_CO_FORBIDDEN      = "The owner '%s' of the pattern '%s' isn't allowed."
 	ERR_CO_MISSING        = "The CODEOWNERS file is missing."
+	ERR_CO_MULTIPLE       = "There's more than one CODEOWNERS file: '%s'. GitHub only uses '%s'."
+	ERR_CO_OWNERS         = "The pattern '%s' should be owned by '%s'."
 	ERR_CO_SYNTAX         = "The CODEOWNERS file has syntax errors:\n%s"
+	ERR_CO_TEAM           = "The owner '%s' of the pattern '%s' should be a team."
+	ERR_CO_UNKNOWN        = "The owner '%s' isn't a known user or team."
+	ERR_CO_VISIBILITY     = "Couldn't verify the owner '%s'. There's a visibility issue here."
+
+	ERR_COLLABORATORS_ACCESS     = "The user '%s' is an %s with the permission '%s'."
+	ERR_COLLABORATORS_INVITATION = "The invitation for '%s' with the permission '%s' has been pending for %d days."
+	ERR_COLLABORATORS_OUTSIDE    = "The outside collaborator '%s' has the permission '%s' and isn't allowed."
+	ERR_COLLABORATORS_VISIBILITY = "Couldn't pull collaborators or invitations. There's a visibility issue here."
+
+	ERR_PROTECTION_CHECKS     = "The branch '%s' should require the status check '%s'."
+	ERR_PROTECTION_MISSING    = "The branch '%s' isn't protected."
+	ERR_PROTECTION_SETTING    = "The branch '%s' should have '%s' set to '%v', not '%v'."
+	ERR_PROTECTION_VISIBILITY = "Couldn't pull the protection for branch '%s'. There's a visibility issue here."
+
+	ERR_RULESET_CHECKS     = "The branch '%s' should require the status check '%s' through a ruleset."
+	ERR_RULESET_MISSING    = "The ruleset '%s' doesn't apply to the branch '%s'."
+	ERR_RULESET_RULE       = "The branch '%s' should have a '%s' rule."
+	ERR_RULESET_VISIBILITY = "Couldn't pull the rules for branch '%s'. There's a visibility issue here."
+	ERR_RULESET_WEAKER     = "The '%s' rule on the branch '%s' should have '%s' set to '%v', not '%v'."
+
+	ERR_SETTINGS_DIFFERENT  = "The setting '%s' should be '%v', not '%v'."
+	ERR_SETTINGS_VISIBILITY = "Couldn't read the setting '%s'. There's a visibility issue here."
+
+	ERR_FILES_FORBIDDEN = "The file '%s' is present and shouldn't be."
+	ERR_FILES_MISSING   = "The file '%s' is missing."
+
+	ERR_MANAGED_DIFFERENT = "The file '%s' doesn't match its template (%s)."
+	ERR_MANAGED_MISSING   = "The managed file '%s' is missing."
+
+	ERR_ALERTS_OPEN       = "The %s alerts of '%s' severity include %d open longer than %d days:\n%s"
+	ERR_ALERTS_VISIBILITY = "Couldn't pull the %s alerts. There's a visibility issue here."
+
+	ERR_SECURITY_DISABLED   = "The security feature '%s' should be enabled."
+	ERR_SECURITY_VISIBILITY = "Couldn't read the status of the security feature '%s'. There's a visibility issue here."
 )
+
+// The message used when a check passes for a repository
+const MSG_CHECK_PASSED = "The %s check passed."
+
+// Stable identifiers for each rule a result can be reported under. These are
+// part of Warden's output formats so they shouldn't change once released.
+const (
+	RULE_ACCESS_EXPIRED    = "access.expired"
+	RULE_ACCESS_EXPIRING   = "access.expiring"
+	RULE_ACCESS_EXTRA      = "access.extra"
+	RULE_ACCESS_INHERITED  = "access.inherited"
+	RULE_ACCESS_MISSING    = "access.missing"
+	RULE_ACCESS_DIFFERENT  = "access.different"
+	RULE_ACCESS_STRATEGY   = "access.strategy"
+	RULE_ACCESS_VISIBILITY = "access.visibility"
+	RULE_BRANCH_DEFAULT    = "branch.default"
+	RULE_LABEL_EXTRA       = "label.extra"
+	RULE_LABEL_MISSING     = "label.missing"
+	RULE_LICENSE_DIFFERENT = "license.different"
+	RULE_LICENSE_MISSING   = "license.missing"
+	RULE_CO_ACCESS         = "codeowners.access"
+	RULE_CO_CATCHALL       = "codeowners.catchall"
+	RULE_CO_DIFFERENT      = "codeowners.different"
+	RULE_CO_FORBIDDEN      = "codeowners.forbidden"
+	RULE_CO_MISSING        = "codeowners.missing"
+	RULE_CO_MULTIPLE       = "codeowners.multiple"
+	RULE_CO_OWNERS         = "codeowners.owners"
+	RULE_CO_SYNTAX         = "codeowners.syntax"
+	RULE_CO_TEAM           = "codeowners.team"
+	RULE_CO_UNKNOWN        = "codeowners.unknown"
+	RULE_CO_VISIBILITY     = "codeowners.visibility"
+
+	RULE_COLLABORATORS_ACCESS     = "collaborators.access"
+	RULE_COLLABORATORS_INVITATION = "collaborators.invitation"
+	RULE_COLLABORATORS_OUTSIDE    = "collaborators.outside"
+	RULE_COLLABORATORS_VISIBILITY = "collaborators.visibility"
+
+	RULE_PROTECTION_CHECKS     = "protection.checks"
+	RULE_PROTECTION_MISSING    = "protection.missing"
+	RULE_PROTECTION_SETTING    = "protection.setting"
+	RULE_PROTECTION_VISIBILITY = "protection.visibility"
+
+	RULE_RULESET_CHECKS     = "ruleset.checks"
+	RULE_RULESET_MISSING    = "ruleset.missing"
+	RULE_RULESET_RULE       = "ruleset.rule"
+	RULE_RULESET_VISIBILITY = "ruleset.visibility"
+	RULE_RULESET_WEAKER     = "ruleset.weaker"
+
+	RULE_SETTINGS_DIFFERENT  = "settings.different"
+	RULE_SETTINGS_VISIBILITY = "settings.visibility"
+
+	RULE_FILES_FORBIDDEN = "files.forbidden"
+	RULE_FILES_MISSING   = "files.missing"
+
+	RULE_MANAGED_DIFFERENT = "managed.different"
+	RULE_MANAGED_MISSING   = "managed.missing"
+
+	RULE_ALERTS_OPEN       = "alerts.open"
+	RULE_ALERTS_VISIBILITY = "alerts.visibility"
+
+	RULE_SECURITY_DISABLED   = "security.disabled"
+	RULE_SECURITY_VISIBILITY = "security.visibility"
+)
+
+// The message template used by each rule
+var ruleMessages = map[string]string{
+	RULE_ACCESS_EXPIRED:    ERR_ACCESS_EXPIRED,
+	RULE_ACCESS_EXPIRING:   ERR_ACCESS_EXPIRING,
+	RULE_ACCESS_EXTRA:      ERR_ACCESS_EXTRA,
+	RULE_ACCESS_INHERITED:  ERR_ACCESS_INHERITED,
+	RULE_ACCESS_MISSING:    ERR_ACCESS_MISSING,
+	RULE_ACCESS_DIFFERENT:  ERR_ACCESS_DIFFERENT,
+	RULE_ACCESS_STRATEGY:   ERR_ACCESS_STRATEGY,
+	RULE_ACCESS_VISIBILITY: ERR_ACCESS_VISIBILITY,
+	RULE_BRANCH_DEFAULT:    ERR_BRANCH_DEFAULT,
+	RULE_LABEL_EXTRA:       ERR_LABEL_EXTRA,
+	RULE_LABEL_MISSING:     ERR_LABEL_MISSING,
+	RULE_LICENSE_DIFFERENT: ERR_LICENSE_DIFFERENT,
+	RULE_LICENSE_MISSING:   ERR_LICENSE_MISSING,
+	RULE_CO_ACCESS:         ERR_CO_ACCESS,
+	RULE_CO_CATCHALL:       ERR_CO_CATCHALL,
+	RULE_CO_DIFFERENT:      ERR_CO_DIFFERENT,
+	RULE_CO_FORBIDDEN:      ERR_CO_FORBIDDEN,
+	RULE_CO_MISSING:        ERR_CO_MISSING,
+	RULE_CO_MULTIPLE:       ERR_CO_MULTIPLE,
+	RULE_CO_OWNERS:         ERR_CO_OWNERS,
+	RULE_CO_SYNTAX:         ERR_CO_SYNTAX,
+	RULE_CO_TEAM:           ERR_CO_TEAM,
+	RULE_CO_UNKNOWN:        ERR_CO_UNKNOWN,
+	RULE_CO_VISIBILITY:     ERR_CO_VISIBILITY,
+
+	RULE_COLLABORATORS_ACCESS:     ERR_COLLABORATORS_ACCESS,
+	RULE_COLLABORATORS_INVITATION: ERR_COLLABORATORS_INVITATION,
+	RULE_COLLABORATORS_OUTSIDE:    ERR_COLLABORATORS_OUTSIDE,
+	RULE_COLLABORATORS_VISIBILITY: ERR_COLLABORATORS_VISIBILITY,
+
+	RULE_PROTECTION_CHECKS:     ERR_PROTECTION_CHECKS,
+	RULE_PROTECTION_MISSING:    ERR_PROTECTION_MISSING,
+	RULE_PROTECTION_SETTING:    ERR_PROTECTION_SETTING,
+	RULE_PROTECTION_VISIBILITY: ERR_PROTECTION_VISIBILITY,
+
+	RULE_RULESET_CHECKS:     ERR_RULESET_CHECKS,
+	RULE_RULESET_MISSING:    ERR_RULESET_MISSING,
+	RULE_RULESET_RULE:       ERR_RULESET_RULE,
+	RULE_RULESET_VISIBILITY: ERR_RULESET_VISIBILITY,
+	RULE_RULESET_WEAKER:     ERR_RULESET_WEAKER,
+
+	RULE_SETTINGS_DIFFERENT:  ERR_SETTINGS_DIFFERENT,
+	RULE_SETTINGS_VISIBILITY: ERR_SETTINGS_VISIBILITY,
+
+	RULE_FILES_FORBIDDEN: ERR_FILES_FORBIDDEN,
+	RULE_FILES_MISSING:   ERR_FILES_MISSING,
+
+	RULE_MANAGED_DIFFERENT: ERR_MANAGED_DIFFERENT,
+	RULE_MANAGED_MISSING:   ERR_MANAGED_MISSING,
+
+	RULE_ALERTS_OPEN:       ERR_ALERTS_OPEN,
+	RULE_ALERTS_VISIBILITY: ERR_ALERTS_VISIBILITY,
+
+	RULE_SECURITY_DISABLED:   ERR_SECURITY_DISABLED,
+	RULE_SECURITY_VISIBILITY: ERR_SECURITY_VISIBILITY,
+}
+
+// Returns the check a rule belongs to, which is the portion of the rule ID
+// before the first period.
+func ruleCheck(rule string) string {
+
+	if i := strings.Index(rule, "."); i != -1 {
+		return rule[:i]
+	}
+
+	return rule
+}
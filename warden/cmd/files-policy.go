@@ -0,0 +1,86 @@
+package cmd
+
+import "strings"
+
+// Files that need to be present in, or absent from, the audited branch.
+// Alternatives for a required file are separated by '|', such as
+// 'SECURITY.md|.github/SECURITY.md', and any one of them satisfies it.
+type filesPolicy struct {
+	Required  []string `yaml:"required"`
+	Forbidden []string `yaml:"forbidden"`
+	Tags      []string `yaml:"tags"`
+}
+
+// Checks for required and forbidden files on the audited branch
+type filesCheck struct{}
+
+func init() {
+	registerCheck(filesCheck{})
+}
+
+func (this filesCheck) ID() string {
+	return "files"
+}
+
+func (this filesCheck) Description() string {
+	return "The files the policy requires are present and the files it forbids are absent."
+}
+
+func (this filesCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, fPolicy := range policy.Files {
+		if tagsMatched(fPolicy.Tags, snapshot.repo.Tags()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this filesCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	for _, fPolicy := range policy.Files {
+
+		if !tagsMatched(fPolicy.Tags, snapshot.repo.Tags()) {
+			continue
+		}
+
+		for _, required := range fPolicy.Required {
+
+			var found bool
+			var err error
+
+			for _, path := range strings.Split(required, "|") {
+
+				_, found, err = snapshot.File(strings.TrimSpace(path))
+				if err != nil {
+					return nil, err
+				}
+
+				if found {
+					break
+				}
+			}
+
+			if !found {
+				results.add(snapshot.repo, RESULT_ERROR, RULE_FILES_MISSING, ERR_FILES_MISSING, strings.ReplaceAll(required, "|", "' or '"))
+			}
+		}
+
+		for _, path := range fPolicy.Forbidden {
+
+			_, found, err := snapshot.File(path)
+			if err != nil {
+				return nil, err
+			}
+
+			if found {
+				results.add(snapshot.repo, RESULT_ERROR, RULE_FILES_FORBIDDEN, ERR_FILES_FORBIDDEN, path)
+			}
+		}
+	}
+
+	return results, nil
+}
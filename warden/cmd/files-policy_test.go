@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestFilesCheck(t *testing.T) {
+
+	readme := "# Sonar"
+
+	policy := &PolicyFile{Files: []filesPolicy{{
+		Required:  []string{"README.md|README|docs/README.md", "SECURITY.md|.github/SECURITY.md", "CONTRIBUTING.md"},
+		Forbidden: []string{".env"},
+	}}}
+
+	tcs := []struct {
+		files map[string]*string
+		rules []string
+	}{
+		{
+			files: map[string]*string{"README": &readme, ".github/SECURITY.md": &readme, "CONTRIBUTING.md": &readme},
+			rules: nil,
+		},
+		{
+			files: map[string]*string{"docs/README.md": &readme, ".env": &readme},
+			rules: []string{RULE_FILES_MISSING, RULE_FILES_MISSING, RULE_FILES_FORBIDDEN},
+		},
+	}
+
+	for i, tc := range tcs {
+
+		snapshot := testSnapshot(t, nil, nil)
+
+		// every path is known so that nothing is fetched
+		for _, path := range []string{"README.md", "README", "docs/README.md", "SECURITY.md", ".github/SECURITY.md", "CONTRIBUTING.md", ".env"} {
+			snapshot.files[path] = tc.files[path]
+		}
+
+		results, err := filesCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+
+		if i == 1 && results[0].String() != "The file 'SECURITY.md' or '.github/SECURITY.md' is missing." {
+			t.Errorf("Want the alternatives in the message, got %s", results[0].String())
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyFl bool
+
+	fixCmd = &cobra.Command{
+		Use:   "fix",
+		Short: "Plans, and optionally applies, changes so that repos meet a set of policy",
+		Long: `Plans, and optionally applies, changes so that repos meet a set of policy.
+Repositories are audited the same way as 'warden audit' and a change is planned for each failure that can be fixed automatically.
+Nothing is changed unless the '--apply' flag is used.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if concurrencyFl < 1 {
+				return errors.New("The '--concurrency' flag needs to be at least 1.")
+			}
+
+			repoFile, _, err := loadRepositoriesFile(repositoriesFileFl)
+			if err != nil {
+				return err
+			}
+
+			policy, _, err := loadPolicyFile(policyFileFl)
+			if err != nil {
+				return err
+			}
+
+			waivers, err := loadWaiversFile(waiversFileFl)
+			if err != nil {
+				return err
+			}
+
+			client, err := newGitHubClient(maxWaitFl)
+			if err != nil {
+				return err
+			}
+
+			group, err := repoFile.Group(groupFl)
+			if err != nil {
+				return err
+			}
+
+			repos, err := WardenRepos(group.GetRepositories(childrenFl))
+			if err != nil {
+				return err
+			}
+
+			results, err := auditRepos(repos, policy, client, concurrencyFl)
+			if err != nil {
+				return err
+			}
+
+			results = waivers.apply(results, time.Now())
+
+			var plan []fixChange
+			var manual auditResults
+
+			for _, result := range results.ByType(RESULT_ERROR) {
+
+				change := planChange(result, policy)
+				if change == nil {
+					manual = append(manual, result)
+					continue
+				}
+
+				plan = append(plan, *change)
+			}
+
+			if len(plan) == 0 && len(manual) == 0 {
+				fmt.Println("Nothing to fix, the audit has no policy failures.")
+				return nil
+			}
+
+			var curRepo string
+			failed := 0
+
+			for _, change := range plan {
+
+				if curRepo != change.repository.ToHTTPS() {
+					curRepo = change.repository.ToHTTPS()
+					fmt.Printf("%s:\n", curRepo)
+				}
+
+				if !applyFl {
+					fmt.Printf("  %s %s\n", change.action, change.description)
+					continue
+				}
+
+				err := change.apply(context.Background(), client)
+				if err != nil {
+					failed++
+					fmt.Printf("  \033[31mx\033[0m %s: %s\n", change.description, err)
+				} else {
+					fmt.Printf("  \033[32m%s\033[0m %s\n", change.action, change.description)
+				}
+			}
+
+			if len(manual) > 0 {
+
+				fmt.Println("\nThese failures need to be fixed manually:")
+
+				for _, result := range manual {
+					fmt.Printf("  %s: %s\n", result.repository.ToHTTPS(), result)
+				}
+			}
+
+			fmt.Println("") // intentional
+
+			if !applyFl {
+				fmt.Printf("%d changes planned. Run again with '--apply' to make them.\n", len(plan))
+				return nil
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d changes failed to apply.", failed, len(plan))
+			}
+
+			fmt.Printf("%d changes applied.\n", len(plan))
+
+			return nil
+		},
+	}
+)
+
+func init() {
+
+	AddChildrenFlag(fixCmd)
+	AddGroupFlag(fixCmd)
+	AddMaxWaitFlag(fixCmd)
+	AddPolicyFileFlag(fixCmd)
+	AddRepositoriesFileFlag(fixCmd)
+	AddWaiversFileFlag(fixCmd)
+
+	fixCmd.PersistentFlags().BoolVar(&applyFl, "apply", false, "make the planned changes instead of only printing them")
+	fixCmd.PersistentFlags().StringVar(&branchFl, "branch", "", "git branch to fix (for applicable polcies)")
+	fixCmd.PersistentFlags().IntVar(&concurrencyFl, "concurrency", 1, "number of repositories to audit at the same time")
+
+	rootCmd.AddCommand(fixCmd)
+}
+
+// A single change to make to a repository
+type fixChange struct {
+	repository  *wardenRepo
+	action      string // '+' to add, '-' to remove, '~' to modify
+	description string
+	apply       func(ctx context.Context, client *github.Client) error
+}
+
+// Returns the change that fixes a failed result, or nil when the failure
+// can't be fixed automatically.
+func planChange(result auditResult, policy *PolicyFile) *fixChange {
+
+	repo := result.repository
+
+	switch result.rule {
+	case RULE_BRANCH_DEFAULT:
+
+		branch := policy.DefaultBranch
+
+		return &fixChange{repo, "~", fmt.Sprintf("set the default branch to '%s'", branch), func(ctx context.Context, client *github.Client) error {
+
+			_, _, err := client.Repositories.Edit(ctx, repo.Owner, repo.Name, &github.Repository{DefaultBranch: &branch})
+			return err
+		}}
+	case RULE_LABEL_MISSING:
+
+		label := result.values[0].(string)
+
+		return &fixChange{repo, "+", fmt.Sprintf("create the label '%s'", label), func(ctx context.Context, client *github.Client) error {
+
+			_, _, err := client.Issues.CreateLabel(ctx, repo.Owner, repo.Name, &github.Label{Name: &label, Color: github.String(DEFAULT_LABEL_COLOR)})
+			return err
+		}}
+	case RULE_LABEL_EXTRA:
+
+		label := result.values[0].(string)
+
+		return &fixChange{repo, "-", fmt.Sprintf("delete the label '%s'", label), func(ctx context.Context, client *github.Client) error {
+
+			_, err := client.Issues.DeleteLabel(ctx, repo.Owner, repo.Name, label)
+			return err
+		}}
+	case RULE_ACCESS_MISSING, RULE_ACCESS_DIFFERENT:
+
+		slug := result.values[0].(string)
+
+		action, verb := "+", "grant"
+		if result.rule == RULE_ACCESS_DIFFERENT {
+			action, verb = "~", "change"
+		}
+
+		permission := accessPolicyPermission(policy, repo, slug)
+		if permission == "" {
+
+			// not a team, so a direct collaborator. Users outside the org are
+			// invited rather than added.
+			permission = accessPolicyUserPermission(policy, repo, slug)
+			if permission == "" {
+				return nil
+			}
+
+			return &fixChange{repo, action, fmt.Sprintf("%s the user '%s' the permission '%s'", verb, slug, permission), func(ctx context.Context, client *github.Client) error {
+
+				_, _, err := client.Repositories.AddCollaborator(ctx, repo.Owner, repo.Name, slug, &github.RepositoryAddCollaboratorOptions{Permission: apiPermission(permission)})
+				return err
+			}}
+		}
+
+		return &fixChange{repo, action, fmt.Sprintf("%s the team '%s/%s' the permission '%s'", verb, repo.Owner, slug, permission), func(ctx context.Context, client *github.Client) error {
+
+			_, err := client.Teams.AddTeamRepoBySlug(ctx, repo.Owner, slug, repo.Owner, repo.Name, &github.TeamAddTeamRepoOptions{Permission: apiPermission(permission)})
+			return err
+		}}
+	case RULE_ACCESS_EXTRA, RULE_ACCESS_EXPIRED:
+
+		team := userPermission{User: result.values[0].(string)}
+
+		if team.IsUser() {
+
+			// the owner of a personal repo shows up as a collaborator but can't be
+			// removed from their own repo
+			if strings.EqualFold(team.User, repo.Owner) {
+				return nil
+			}
+
+			return &fixChange{repo, "-", fmt.Sprintf("remove the collaborator '%s'", team.User), func(ctx context.Context, client *github.Client) error {
+
+				_, err := client.Repositories.RemoveCollaborator(ctx, repo.Owner, repo.Name, team.User)
+				return err
+			}}
+		}
+
+		return &fixChange{repo, "-", fmt.Sprintf("remove the team '%s'", team.User), func(ctx context.Context, client *github.Client) error {
+
+			_, err := client.Teams.RemoveTeamRepoBySlug(ctx, team.Owner(), team.UserSlug(), repo.Owner, repo.Name)
+			return err
+		}}
+	case RULE_CO_MISSING, RULE_CO_DIFFERENT:
+
+		content, ok := codeownersPolicyContent(policy, repo)
+		if !ok {
+			return nil
+		}
+
+		branch := branchFl
+
+		// update the file GitHub uses rather than adding another one
+		path := CODEOWNERS_PATH
+		if len(result.values) > 0 {
+			path = result.values[0].(string)
+		}
+
+		return &fixChange{repo, "~", "commit the CODEOWNERS file from the policy", func(ctx context.Context, client *github.Client) error {
+			return commitCodeowners(ctx, client, repo, branch, path, content)
+		}}
+	}
+
+	return nil
+}
+
+// The color used for labels Warden creates
+const DEFAULT_LABEL_COLOR = "ededed"
+
+// Returns the permission the policy expects a team to have on a repo. When
+// more than one access policy applies, the first one wins.
+func accessPolicyPermission(policy *PolicyFile, repo *wardenRepo, slug string) string {
+
+	for _, accessPolicy := range policy.Access {
+
+		if !tagsMatched(accessPolicy.Tags, repo.Tags()) {
+			continue
+		}
+
+		for _, user := range accessPolicy.Permissions {
+			if user.IsTeam() && user.Owner() == repo.Owner && user.UserSlug() == slug {
+				return user.Permission
+			}
+		}
+	}
+
+	return ""
+}
+
+// Returns the permission the policy expects a user to have on a repo. When
+// more than one access policy applies, the first one wins.
+func accessPolicyUserPermission(policy *PolicyFile, repo *wardenRepo, login string) string {
+
+	for _, accessPolicy := range policy.Access {
+
+		if !tagsMatched(accessPolicy.Tags, repo.Tags()) {
+			continue
+		}
+
+		for _, user := range accessPolicy.Permissions {
+			if user.IsUser() && strings.EqualFold(user.User, login) {
+				return user.Permission
+			}
+		}
+	}
+
+	return ""
+}
+
+// The API calls 'read' access 'pull'
+func apiPermission(permission string) string {
+
+	if permission == "read" {
+		return "pull"
+	}
+
+	return permission
+}
+
+// Returns the CODEOWNERS content the policy expects for a repo. When more
+// than one codeowners policy with content applies, the first one wins.
+func codeownersPolicyContent(policy *PolicyFile, repo *wardenRepo) (string, bool) {
+
+	for _, coPolicy := range policy.Codeowners {
+		if coPolicy.Content != "" && tagsMatched(coPolicy.Tags, repo.Tags()) {
+			return coPolicy.Content, true
+		}
+	}
+
+	return "", false
+}
+
+// Creates or updates a CODEOWNERS file on a branch. An empty branch means the
+// repo's default branch.
+func commitCodeowners(ctx context.Context, client *github.Client, repo *wardenRepo, branch, path, content string) error {
+
+	opts := &github.RepositoryContentFileOptions{
+		Message: github.String("Update CODEOWNERS to match policy"),
+		Content: []byte(content),
+	}
+
+	if branch != "" {
+		opts.Branch = &branch
+	}
+
+	file, _, resp, err := client.Repositories.GetContents(ctx, repo.Owner, repo.Name, path, &github.RepositoryContentGetOptions{Ref: branch})
+	if err != nil && (resp == nil || resp.StatusCode != 404) {
+		return err
+	}
+
+	if file != nil {
+		opts.SHA = file.SHA
+		_, _, err = client.Repositories.UpdateFile(ctx, repo.Owner, repo.Name, path, opts)
+	} else {
+		_, _, err = client.Repositories.CreateFile(ctx, repo.Owner, repo.Name, path, opts)
+	}
+
+	return err
+}
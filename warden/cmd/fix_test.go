@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/repowarden/cli/warden/vcsurl"
+)
+
+func TestPlanChange(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	policy := &PolicyFile{
+		DefaultBranch: "trunk",
+		Access: []accessPolicy{{
+			Permissions: []userPermission{
+				{User: "felicianotech/writers", Permission: "read"},
+				{User: "jdoe", Permission: "push"},
+				{User: "felicianotech/admins", Permission: "admin"},
+			},
+		}},
+		Codeowners: []codeownersPolicy{{Content: "* @felicianotech/writers\n"}},
+	}
+
+	tcs := []struct {
+		rule        string
+		values      []any
+		action      string
+		description string
+		request     string // the API call the change makes
+	}{
+		{rule: RULE_BRANCH_DEFAULT, values: []any{"trunk", "main"}, action: "~", description: "set the default branch to 'trunk'", request: "PATCH /repos/felicianotech/sonar"},
+		{rule: RULE_LABEL_MISSING, values: []any{"bug"}, action: "+", description: "create the label 'bug'", request: "POST /repos/felicianotech/sonar/labels"},
+		{rule: RULE_LABEL_EXTRA, values: []any{"wontfix"}, action: "-", description: "delete the label 'wontfix'", request: "DELETE /repos/felicianotech/sonar/labels/wontfix"},
+		{rule: RULE_ACCESS_MISSING, values: []any{"writers"}, action: "+", description: "grant the team 'felicianotech/writers' the permission 'read'", request: "PUT /orgs/felicianotech/teams/writers/repos/felicianotech/sonar"},
+		{rule: RULE_ACCESS_DIFFERENT, values: []any{"admins", "admin", "push"}, action: "~", description: "change the team 'felicianotech/admins' the permission 'admin'", request: "PUT /orgs/felicianotech/teams/admins/repos/felicianotech/sonar"},
+		{rule: RULE_ACCESS_MISSING, values: []any{"jdoe"}, action: "+", description: "grant the user 'jdoe' the permission 'push'", request: "PUT /repos/felicianotech/sonar/collaborators/jdoe"},
+		{rule: RULE_ACCESS_DIFFERENT, values: []any{"jdoe", "push", "admin"}, action: "~", description: "change the user 'jdoe' the permission 'push'", request: "PUT /repos/felicianotech/sonar/collaborators/jdoe"},
+		{rule: RULE_ACCESS_MISSING, values: []any{"unlisted"}},
+		{rule: RULE_ACCESS_EXTRA, values: []any{"felicianotech/others"}, action: "-", description: "remove the team 'felicianotech/others'", request: "DELETE /orgs/felicianotech/teams/others/repos/felicianotech/sonar"},
+		{rule: RULE_ACCESS_EXTRA, values: []any{"contractor"}, action: "-", description: "remove the collaborator 'contractor'", request: "DELETE /repos/felicianotech/sonar/collaborators/contractor"},
+		{rule: RULE_ACCESS_EXPIRED, values: []any{"contractor", "2026-06-09"}, action: "-", description: "remove the collaborator 'contractor'", request: "DELETE /repos/felicianotech/sonar/collaborators/contractor"},
+		{rule: RULE_ACCESS_EXPIRED, values: []any{"felicianotech/incident", "2026-06-09"}, action: "-", description: "remove the team 'felicianotech/incident'", request: "DELETE /orgs/felicianotech/teams/incident/repos/felicianotech/sonar"},
+		{rule: RULE_ACCESS_EXTRA, values: []any{"felicianotech"}},
+		{rule: RULE_ACCESS_EXTRA, values: []any{"FelicianoTech"}},
+		{rule: RULE_CO_MISSING, action: "~", description: "commit the CODEOWNERS file from the policy", request: "PUT /repos/felicianotech/sonar/contents/.github/CODEOWNERS"},
+		{rule: RULE_CO_DIFFERENT, values: []any{"docs/CODEOWNERS"}, action: "~", description: "commit the CODEOWNERS file from the policy", request: "PUT /repos/felicianotech/sonar/contents/docs/CODEOWNERS"},
+		{rule: RULE_LICENSE_MISSING},
+	}
+
+	for i, tc := range tcs {
+
+		var requests []string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			// CODEOWNERS files are looked up before being committed
+			if r.Method == http.MethodGet {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			fmt.Fprint(w, "{}")
+		}))
+
+		client := github.NewClient(nil)
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+
+		result := auditResult{repository: repo, resultType: RESULT_ERROR, rule: tc.rule, message: ruleMessages[tc.rule], values: tc.values}
+
+		change := planChange(result, policy)
+
+		if tc.action == "" {
+
+			if change != nil {
+				t.Errorf("Case %d: Want no change for %s %v, got '%s'", i+1, tc.rule, tc.values, change.description)
+			}
+
+			server.Close()
+			continue
+		}
+
+		if change == nil {
+			t.Errorf("Case %d: Want a change for %s %v, got none", i+1, tc.rule, tc.values)
+			server.Close()
+			continue
+		}
+
+		if change.action != tc.action || change.description != tc.description {
+			t.Errorf("Case %d: Want '%s %s', got '%s %s'", i+1, tc.action, tc.description, change.action, change.description)
+		}
+
+		if err := change.apply(context.Background(), client); err != nil {
+			t.Errorf("Case %d: %s", i+1, err)
+		}
+
+		if len(requests) != 1 || requests[0] != tc.request {
+			t.Errorf("Case %d: Want the request '%s', got %v", i+1, tc.request, requests)
+		}
+
+		server.Close()
+	}
+}
+
+func TestPlanChangeWithoutCodeowners(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	result := auditResult{repository: repo, resultType: RESULT_ERROR, rule: RULE_CO_MISSING, message: ERR_CO_MISSING}
+
+	// a policy that only checks rules has no content to commit
+	policy := &PolicyFile{Codeowners: []codeownersPolicy{{CatchAll: true}}}
+
+	if change := planChange(result, policy); change != nil {
+		t.Errorf("Want no change without CODEOWNERS content, got '%s'", change.description)
+	}
+}
+
+func TestAccessPolicyPermission(t *testing.T) {
+
+	url, err := vcsurl.Parse("https://github.com/felicianotech/hugo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := WardenRepo(url, []string{"hugo"})
+
+	policy := &PolicyFile{Access: []accessPolicy{
+		{Permissions: []userPermission{{User: "felicianotech/writers", Permission: "read"}}, Tags: []string{"other"}},
+		{Permissions: []userPermission{{User: "felicianotech/writers", Permission: "push"}, {User: "JDoe", Permission: "triage"}}},
+		{Permissions: []userPermission{{User: "felicianotech/writers", Permission: "admin"}, {User: "jdoe", Permission: "admin"}}},
+	}}
+
+	// policies for other tags are skipped and the first match wins
+	if permission := accessPolicyPermission(policy, repo, "writers"); permission != "push" {
+		t.Errorf("Want the team permission 'push', got '%s'", permission)
+	}
+
+	// users aren't teams, so they fall back to the user lookup
+	if permission := accessPolicyPermission(policy, repo, "jdoe"); permission != "" {
+		t.Errorf("Want no team permission for a user, got '%s'", permission)
+	}
+
+	if permission := accessPolicyUserPermission(policy, repo, "jdoe"); permission != "triage" {
+		t.Errorf("Want the user permission 'triage', got '%s'", permission)
+	}
+
+	if permission := accessPolicyUserPermission(policy, repo, "writers"); permission != "" {
+		t.Errorf("Want no user permission for a team, got '%s'", permission)
+	}
+}
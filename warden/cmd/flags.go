@@ -1,6 +1,10 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
 
 var childrenFl bool
 
@@ -16,6 +20,13 @@ func AddGroupFlag(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&groupFl, "group", "all", "which group to filter repositories by, default 'all'")
 }
 
+var maxWaitFl time.Duration
+
+func AddMaxWaitFlag(cmd *cobra.Command) {
+
+	cmd.PersistentFlags().DurationVar(&maxWaitFl, "max-wait", 15*time.Minute, "the most time to spend waiting on GitHub rate limits and retries, default '15m'")
+}
+
 var policyFileFl string
 
 func AddPolicyFileFlag(cmd *cobra.Command) {
@@ -29,3 +40,10 @@ func AddRepositoriesFileFlag(cmd *cobra.Command) {
 
 	cmd.PersistentFlags().StringVar(&repositoriesFileFl, "repositoriesFile", "", "file containing rules (default is ./repositories.y[a]ml)")
 }
+
+var waiversFileFl string
+
+func AddWaiversFileFlag(cmd *cobra.Command) {
+
+	cmd.PersistentFlags().StringVar(&waiversFileFl, "waiversFile", "", "file containing policy exceptions (default is ./waivers.y[a]ml, if present)")
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// how many times a request that failed with a 5xx error will be retried
+	MAX_SERVER_RETRIES = 5
+
+	// GitHub recommends waiting at least a minute when a secondary rate limit
+	// response doesn't say how long to wait
+	SECONDARY_RATE_WAIT = time.Minute
+)
+
+// Creates a GitHub client using the configured token. The client waits out
+// rate limits and retries server errors for up to maxWait from now.
+func newGitHubClient(maxWait time.Duration) (*github.Client, error) {
+
+	ghToken := viper.GetString("GH_TOKEN")
+	if ghToken == "" {
+		return nil, errors.New("GitHub credentials were not found. Please run `warden configure`.")
+	}
+
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: ghToken},
+	)
+	tc := oauth2.NewClient(context.Background(), ts)
+
+	tc.Transport = newRetryTransport(tc.Transport, maxWait)
+
+	return github.NewClient(tc), nil
+}
+
+// An http.RoundTripper that respects GitHub's rate limits and retries
+// transient errors. Waiting stops at a deadline shared by every request made
+// through the transport, so concurrent requests don't each get the full wait.
+type retryTransport struct {
+	base     http.RoundTripper
+	maxWait  time.Duration
+	deadline time.Time
+}
+
+// Creates a retryTransport that waits until at most maxWait from now
+func newRetryTransport(base http.RoundTripper, maxWait time.Duration) *retryTransport {
+
+	return &retryTransport{
+		base:     base,
+		maxWait:  maxWait,
+		deadline: time.Now().Add(maxWait),
+	}
+}
+
+func (this *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	for attempt := 0; ; attempt++ {
+
+		if attempt > 0 && req.Body != nil {
+
+			if req.GetBody == nil {
+				return nil, errors.New("The request to GitHub can't be retried.")
+			}
+
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := this.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		wait, reason := this.waitFor(resp, attempt)
+		if wait == 0 {
+			return resp, nil
+		}
+
+		// a successful response that used up the rate limit is returned once the
+		// limit resets, otherwise the GitHub client would refuse the next request
+		retry := resp.StatusCode >= 400
+
+		if retry {
+			resp.Body.Close()
+		}
+
+		if err := this.sleep(req.Context(), wait, reason); err != nil {
+			if !retry {
+				resp.Body.Close()
+			}
+
+			return nil, err
+		}
+
+		if !retry {
+			return resp, nil
+		}
+	}
+}
+
+// Decides how long to wait before the next request, if at all, based on the
+// response. The reason is used for the notice printed while waiting.
+func (this *retryTransport) waitFor(resp *http.Response, attempt int) (time.Duration, string) {
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+
+		if wait, ok := retryAfter(resp); ok {
+			return wait, "GitHub secondary rate limit reached"
+		}
+
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			return untilReset(resp), "GitHub rate limit reached"
+		}
+
+		// a 403 can also mean missing permissions, so only secondary rate
+		// limits without headers are retried, based on the message
+		if isSecondaryRateLimit(resp) {
+			return SECONDARY_RATE_WAIT, "GitHub secondary rate limit reached"
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return SECONDARY_RATE_WAIT, "GitHub rate limit reached"
+		}
+	case resp.StatusCode >= 500 && attempt < MAX_SERVER_RETRIES:
+		return time.Duration(1<<attempt) * time.Second, fmt.Sprintf("GitHub returned '%s'", resp.Status)
+	case resp.StatusCode < 300 && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return untilReset(resp), "GitHub rate limit used up"
+	}
+
+	return 0, ""
+}
+
+// Sleeps for the given duration unless doing so would go past the deadline
+func (this *retryTransport) sleep(ctx context.Context, wait time.Duration, reason string) error {
+
+	if time.Now().Add(wait).After(this.deadline) {
+		return fmt.Errorf("%s. Waiting another %s would exceed the maximum wait of %s, see '--max-wait'.", reason, wait.Round(time.Second), this.maxWait)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s, waiting %s before continuing...\n", reason, wait.Round(time.Second))
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Returns the duration from the Retry-After header, if present
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// Returns the time until the primary rate limit resets
+func untilReset(resp *http.Response) time.Duration {
+
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return SECONDARY_RATE_WAIT
+	}
+
+	// a second of padding covers clock differences with GitHub
+	wait := time.Until(time.Unix(reset, 0)) + time.Second
+	if wait < time.Second {
+		wait = time.Second
+	}
+
+	return wait
+}
+
+// Whether the response body describes a secondary rate limit. The body is
+// left readable for the GitHub client.
+func isSecondaryRateLimit(resp *http.Response) bool {
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	message := strings.ToLower(string(body))
+
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse")
+}
+
+// Whether an error from the GitHub client is a 404 response
+func isNotFound(err error) bool {
+
+	var errResp *github.ErrorResponse
+
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
+}
+
+// Whether an error from the GitHub client is a 403 response
+func isForbidden(err error) bool {
+
+	var errResp *github.ErrorResponse
+
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusForbidden
+}
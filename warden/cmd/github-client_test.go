@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Returns a response as the transport would see it from GitHub
+func testResponse(status int, headers map[string]string, body string) *http.Response {
+
+	resp := &http.Response{
+		StatusCode: status,
+		Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+
+	for key, value := range headers {
+		resp.Header.Set(key, value)
+	}
+
+	return resp
+}
+
+func TestRetryTransportWaitFor(t *testing.T) {
+
+	reset := strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10)
+
+	tcs := []struct {
+		resp    *http.Response
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{resp: testResponse(200, nil, ""), min: 0, max: 0},
+		{resp: testResponse(404, nil, ""), min: 0, max: 0},
+		{resp: testResponse(403, nil, `{"message": "Resource not accessible by integration"}`), min: 0, max: 0},
+		{resp: testResponse(403, map[string]string{"Retry-After": "7"}, ""), min: 7 * time.Second, max: 7 * time.Second},
+		{resp: testResponse(429, nil, ""), min: SECONDARY_RATE_WAIT, max: SECONDARY_RATE_WAIT},
+		{resp: testResponse(403, nil, `{"message": "You have exceeded a secondary rate limit."}`), min: SECONDARY_RATE_WAIT, max: SECONDARY_RATE_WAIT},
+		{resp: testResponse(403, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}, ""), min: 29 * time.Second, max: 32 * time.Second},
+		{resp: testResponse(200, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}, ""), min: 29 * time.Second, max: 32 * time.Second},
+		{resp: testResponse(502, nil, ""), attempt: 0, min: time.Second, max: time.Second},
+		{resp: testResponse(502, nil, ""), attempt: 3, min: 8 * time.Second, max: 8 * time.Second},
+		{resp: testResponse(502, nil, ""), attempt: MAX_SERVER_RETRIES, min: 0, max: 0},
+	}
+
+	transport := newRetryTransport(http.DefaultTransport, time.Hour)
+
+	for i, tc := range tcs {
+
+		wait, _ := transport.waitFor(tc.resp, tc.attempt)
+
+		if wait < tc.min || wait > tc.max {
+			t.Errorf("Case %d: Want a wait between %s and %s, got %s", i+1, tc.min, tc.max, wait)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+
+	if wait, ok := retryAfter(testResponse(429, map[string]string{"Retry-After": "3"}, "")); !ok || wait != 3*time.Second {
+		t.Errorf("Want a wait of 3s, got %s", wait)
+	}
+
+	if _, ok := retryAfter(testResponse(429, nil, "")); ok {
+		t.Error("A response without a Retry-After header shouldn't have a wait.")
+	}
+
+	if _, ok := retryAfter(testResponse(429, map[string]string{"Retry-After": "Wed, 21 Oct 2015 07:28:00 GMT"}, "")); ok {
+		t.Error("Only a Retry-After header in seconds should be used.")
+	}
+}
+
+func TestRetryTransportServerErrors(t *testing.T) {
+
+	var requests int32
+
+	// fails once before succeeding
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, time.Minute)}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("Want the request to succeed on the second attempt, got %d after %d requests", resp.StatusCode, requests)
+	}
+}
+
+func TestRetryTransportDeadline(t *testing.T) {
+
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	// the first retry waits a second, which is past the deadline
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 500*time.Millisecond)}
+
+	_, err := client.Get(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "--max-wait") {
+		t.Errorf("Want the maximum wait to be exceeded, got %v", err)
+	}
+
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("Want a single request, got %d", requests)
+	}
+}
+
+func TestRetryTransportConcurrentWaits(t *testing.T) {
+
+	var mu sync.Mutex
+	failed := make(map[string]bool)
+
+	// each path fails once before succeeding
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !failed[r.URL.Path] {
+			failed[r.URL.Path] = true
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	// requests waiting at the same time share the wall-clock deadline rather
+	// than adding their waits together
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 1500*time.Millisecond)}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+
+	for i := range errs {
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			resp, err := client.Get(server.URL + "/" + strconv.Itoa(i))
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			errs[i] = err
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Request %d: Want the retry to fit the deadline, got %s", i+1, err)
+		}
+	}
+}
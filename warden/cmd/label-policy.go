@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+
+	"golang.org/x/exp/slices"
+)
+
+// Checks the repository's issue labels
+type labelCheck struct{}
+
+func init() {
+	registerCheck(labelCheck{})
+}
+
+func (this labelCheck) ID() string {
+	return "label"
+}
+
+func (this labelCheck) Description() string {
+	return "The repository's issue labels match the policy."
+}
+
+func (this labelCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+	return len(policy.Labels) > 0
+}
+
+func (this labelCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+	var names []string
+
+	labels, err := snapshot.Labels()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, label := range labels {
+		names = append(names, label.GetName())
+	}
+
+	switch policy.LabelStrategy {
+	case "available", "":
+
+		// for each label we're checking for
+		for _, label := range policy.Labels {
+
+			if !slices.Contains(names, label) {
+				results.add(
+					snapshot.repo,
+					RESULT_ERROR,
+					RULE_LABEL_MISSING,
+					ERR_LABEL_MISSING,
+					label,
+				)
+			}
+		}
+	case "only":
+
+		// for each label the repo has
+		for _, name := range names {
+
+			if !slices.Contains(policy.Labels, name) {
+				results.add(
+					snapshot.repo,
+					RESULT_ERROR,
+					RULE_LABEL_EXTRA,
+					ERR_LABEL_EXTRA,
+					name,
+				)
+			}
+		}
+	default:
+		return nil, errors.New("The labelStrategy of " + policy.LabelStrategy + " isn't valid.")
+	}
+
+	return results, nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestLabelCheck(t *testing.T) {
+
+	tcs := []struct {
+		strategy string
+		labels   []string
+		rules    []string
+	}{
+		{strategy: "", labels: []string{"bug", "high-priority"}, rules: nil},
+		{strategy: "available", labels: []string{"bug", "wontfix"}, rules: []string{RULE_LABEL_MISSING}},
+		{strategy: "only", labels: []string{"bug", "high-priority"}, rules: nil},
+		{strategy: "only", labels: []string{"bug", "wontfix"}, rules: []string{RULE_LABEL_EXTRA}},
+	}
+
+	for i, tc := range tcs {
+
+		policy := &PolicyFile{Labels: []string{"bug", "high-priority"}, LabelStrategy: tc.strategy}
+
+		snapshot := testSnapshot(t, nil, nil)
+		snapshot.loaded["labels"] = true
+
+		for _, label := range tc.labels {
+			snapshot.labels = append(snapshot.labels, &github.Label{Name: github.String(label)})
+		}
+
+		results, err := labelCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+
+	snapshot := testSnapshot(t, nil, nil)
+	snapshot.loaded["labels"] = true
+
+	_, err := labelCheck{}.Evaluate(&PolicyFile{Labels: []string{"bug"}, LabelStrategy: "some"}, snapshot)
+	if err == nil {
+		t.Error("An invalid label strategy should return an error.")
+	}
+}
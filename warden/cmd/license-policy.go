@@ -0,0 +1,55 @@
+package cmd
+
+import "golang.org/x/exp/slices"
+
+// Which code licenses to allow and for which scope
+type licensePolicy struct {
+	Scope string   `yaml:"scope"`
+	Names []string `yaml:"names"`
+}
+
+// Checks the repository's license against the allowed licenses
+type licenseCheck struct{}
+
+func init() {
+	registerCheck(licenseCheck{})
+}
+
+func (this licenseCheck) ID() string {
+	return "license"
+}
+
+func (this licenseCheck) Description() string {
+	return "The repository has one of the licenses allowed by the policy."
+}
+
+func (this licenseCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+	return policy.License != nil && (policy.License.Scope == snapshot.data.GetVisibility() || policy.License.Scope == "all")
+}
+
+func (this licenseCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	key := snapshot.data.GetLicense().GetKey()
+
+	if key == "" {
+		results.add(
+			snapshot.repo,
+			RESULT_ERROR,
+			RULE_LICENSE_MISSING,
+			ERR_LICENSE_MISSING,
+		)
+	} else if !slices.Contains(policy.License.Names, key) {
+		results.add(
+			snapshot.repo,
+			RESULT_ERROR,
+			RULE_LICENSE_DIFFERENT,
+			ERR_LICENSE_DIFFERENT,
+			policy.License.Names,
+			key,
+		)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestLicenseCheck(t *testing.T) {
+
+	tcs := []struct {
+		visibility string
+		license    string
+		applies    bool
+		rules      []string
+	}{
+		{visibility: "public", license: "mit", applies: true, rules: nil},
+		{visibility: "public", license: "", applies: true, rules: []string{RULE_LICENSE_MISSING}},
+		{visibility: "public", license: "gpl-3.0", applies: true, rules: []string{RULE_LICENSE_DIFFERENT}},
+		{visibility: "private", license: "", applies: false},
+	}
+
+	policy := &PolicyFile{License: &licensePolicy{Scope: "public", Names: []string{"mit", "agpl"}}}
+
+	for i, tc := range tcs {
+
+		data := &github.Repository{Visibility: github.String(tc.visibility)}
+		if tc.license != "" {
+			data.License = &github.License{Key: github.String(tc.license)}
+		}
+
+		snapshot := testSnapshot(t, nil, data)
+
+		if (licenseCheck{}).Applies(policy, snapshot) != tc.applies {
+			t.Errorf("Case %d: Want applies to be %t", i+1, tc.applies)
+			continue
+		}
+
+		if !tc.applies {
+			continue
+		}
+
+		results, err := licenseCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+}
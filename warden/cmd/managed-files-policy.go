@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// A file whose content is managed by a template. The template is rendered
+// with managedFileData so that the file can differ per repository.
+type managedFilePolicy struct {
+	Path         string   `yaml:"path"`
+	Template     string   `yaml:"template"`     // the template itself
+	TemplateFile string   `yaml:"templateFile"` // or a file to read it from
+	Match        string   `yaml:"match"`        // 'exact' (default), 'contains', 'regex', or 'header'
+	Tags         []string `yaml:"tags"`
+
+	tmpl *template.Template
+}
+
+// The repository variables available to managed file templates
+type managedFileData struct {
+	Owner         string
+	Name          string
+	Tags          []string
+	Group         string
+	DefaultBranch string
+}
+
+// Reads and parses the templates of the managed files. Template files are
+// relative to dir, the directory of the policy file.
+func (this *PolicyFile) loadManagedFiles(dir string) error {
+
+	for i := range this.ManagedFiles {
+
+		mfPolicy := &this.ManagedFiles[i]
+
+		if mfPolicy.TemplateFile != "" {
+
+			path := mfPolicy.TemplateFile
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("The template file for the managed file '%s' couldn't be read: %s", mfPolicy.Path, err)
+			}
+
+			mfPolicy.Template = string(content)
+		}
+
+		switch mfPolicy.Match {
+		case "", "exact", "contains", "regex", "header":
+		default:
+			return fmt.Errorf("The match '%s' for the managed file '%s' isn't valid. Options are: exact, contains, regex, header", mfPolicy.Match, mfPolicy.Path)
+		}
+
+		tmpl, err := template.New(mfPolicy.Path).Option("missingkey=error").Parse(mfPolicy.Template)
+		if err != nil {
+			return fmt.Errorf("The template for the managed file '%s' couldn't be parsed: %s", mfPolicy.Path, err)
+		}
+
+		mfPolicy.tmpl = tmpl
+	}
+
+	return nil
+}
+
+// Renders the template for a repository
+func (this managedFilePolicy) render(data managedFileData) (string, error) {
+
+	var b strings.Builder
+
+	err := this.tmpl.Execute(&b, data)
+	if err != nil {
+		return "", fmt.Errorf("The template for the managed file '%s' couldn't be rendered: %s", this.Path, err)
+	}
+
+	return b.String(), nil
+}
+
+// Whether the content of a file matches the rendered template
+func (this managedFilePolicy) matches(content, rendered string) (bool, error) {
+
+	switch this.Match {
+	case "contains":
+		return strings.Contains(content, rendered), nil
+	case "header":
+		return strings.HasPrefix(content, rendered), nil
+	case "regex":
+
+		re, err := regexp.Compile(rendered)
+		if err != nil {
+			return false, fmt.Errorf("The regex for the managed file '%s' isn't valid: %s", this.Path, err)
+		}
+
+		return re.MatchString(content), nil
+	}
+
+	return content == rendered, nil
+}
+
+// Checks the content of files managed by templates
+type managedFilesCheck struct{}
+
+func init() {
+	registerCheck(managedFilesCheck{})
+}
+
+func (this managedFilesCheck) ID() string {
+	return "managed"
+}
+
+func (this managedFilesCheck) Description() string {
+	return "The files managed by the policy match their templates."
+}
+
+func (this managedFilesCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, mfPolicy := range policy.ManagedFiles {
+		if tagsMatched(mfPolicy.Tags, snapshot.repo.Tags()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this managedFilesCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	data := managedFileData{
+		Owner:         snapshot.repo.Owner,
+		Name:          snapshot.repo.Name,
+		Tags:          snapshot.repo.Tags(),
+		Group:         snapshot.repo.Group(),
+		DefaultBranch: snapshot.data.GetDefaultBranch(),
+	}
+
+	for _, mfPolicy := range policy.ManagedFiles {
+
+		if !tagsMatched(mfPolicy.Tags, snapshot.repo.Tags()) {
+			continue
+		}
+
+		content, found, err := snapshot.File(mfPolicy.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			results.add(snapshot.repo, RESULT_ERROR, RULE_MANAGED_MISSING, ERR_MANAGED_MISSING, mfPolicy.Path)
+			continue
+		}
+
+		rendered, err := mfPolicy.render(data)
+		if err != nil {
+			return nil, err
+		}
+
+		matched, err := mfPolicy.matches(content, rendered)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matched {
+
+			match := mfPolicy.Match
+			if match == "" {
+				match = "exact"
+			}
+
+			results.add(snapshot.repo, RESULT_ERROR, RULE_MANAGED_DIFFERENT, ERR_MANAGED_DIFFERENT, mfPolicy.Path, match)
+		}
+	}
+
+	return results, nil
+}
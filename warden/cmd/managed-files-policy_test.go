@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestManagedFilesCheck(t *testing.T) {
+
+	policy := &PolicyFile{ManagedFiles: []managedFilePolicy{
+		{Path: ".editorconfig", Template: "root = true\n"},
+		{Path: "LICENSE", Template: "Copyright (c) {{ .Owner }}", Match: "header"},
+		{Path: ".github/pull_request_template.md", Template: "{{ .Name }}", Match: "contains"},
+		{Path: "README.md", Template: `branch/{{ .DefaultBranch }}\b`, Match: "regex"},
+	}}
+
+	err := policy.loadManagedFiles(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		files map[string]string
+		rules []string
+	}{
+		{
+			files: map[string]string{
+				".editorconfig":                    "root = true\n",
+				"LICENSE":                          "Copyright (c) felicianotech\n\nPermission is hereby granted",
+				".github/pull_request_template.md": "Thanks for contributing to sonar!",
+				"README.md":                        "![CI](https://example.com/branch/trunk)",
+			},
+			rules: nil,
+		},
+		{
+			files: map[string]string{
+				".editorconfig":                    "root = true\nindent_style = tab\n",
+				"LICENSE":                          "MIT License\n\nCopyright (c) felicianotech",
+				".github/pull_request_template.md": "Thanks for contributing!",
+			},
+			rules: []string{RULE_MANAGED_DIFFERENT, RULE_MANAGED_DIFFERENT, RULE_MANAGED_DIFFERENT, RULE_MANAGED_MISSING},
+		},
+	}
+
+	for i, tc := range tcs {
+
+		snapshot := testSnapshot(t, nil, &github.Repository{DefaultBranch: github.String("trunk")})
+
+		for _, mfPolicy := range policy.ManagedFiles {
+
+			snapshot.files[mfPolicy.Path] = nil
+
+			if content, ok := tc.files[mfPolicy.Path]; ok {
+				snapshot.files[mfPolicy.Path] = &content
+			}
+		}
+
+		results, err := managedFilesCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+}
+
+func TestLoadManagedFiles(t *testing.T) {
+
+	policy := &PolicyFile{ManagedFiles: []managedFilePolicy{{Path: "LICENSE", Template: "{{ .Owner", Match: "header"}}}
+	if policy.loadManagedFiles(".") == nil {
+		t.Error("A template that doesn't parse should fail to load.")
+	}
+
+	policy = &PolicyFile{ManagedFiles: []managedFilePolicy{{Path: "LICENSE", Match: "fuzzy"}}}
+	if policy.loadManagedFiles(".") == nil {
+		t.Error("An unknown match should fail to load.")
+	}
+}
+
+func TestLoadManagedFilesTemplateFile(t *testing.T) {
+
+	dir := t.TempDir()
+
+	err := os.Mkdir(filepath.Join(dir, "templates"), 0775)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "templates", "editorconfig"), []byte("root = true\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "policy.yml"), []byte("managedFiles:\n  - path: .editorconfig\n    templateFile: templates/editorconfig\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// template files are found next to the policy file, wherever it's run from
+	policy, _, err := loadPolicyFile(filepath.Join(dir, "policy.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if policy.ManagedFiles[0].Template != "root = true\n" {
+		t.Errorf("Want the template from the template file, got '%s'", policy.ManagedFiles[0].Template)
+	}
+}
+
+func TestExamplePolicyLoads(t *testing.T) {
+
+	// the example is meant to be copied, so it can't depend on other files
+	if _, _, err := loadPolicyFile("../../example.policy.yml"); err != nil {
+		t.Errorf("The example policy should load, got: %s", err)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	failures  int
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// Writes the results as JUnit XML. Each repository is a test suite and each
+// check evaluated against it is a test case. Errors become failures while
+// warnings and notes are attached as output to the test case.
+func writeJUnitReport(w io.Writer, results auditResults) error {
+
+	suites := junitTestSuites{Name: "Warden"}
+	suiteIndexes := make(map[string]int)
+	caseIndexes := make(map[string]int)
+
+	for _, result := range results {
+
+		if result.resultType == RESULT_DEBUG {
+			continue
+		}
+
+		repoURL := result.repository.ToHTTPS()
+		check := ruleCheck(result.rule)
+
+		si, ok := suiteIndexes[repoURL]
+		if !ok {
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: repoURL})
+			si = len(suites.Suites) - 1
+			suiteIndexes[repoURL] = si
+		}
+
+		suite := &suites.Suites[si]
+
+		ci, ok := caseIndexes[repoURL+" "+check]
+		if !ok {
+			suite.TestCases = append(suite.TestCases, junitTestCase{Name: check, ClassName: repoURL})
+			ci = len(suite.TestCases) - 1
+			caseIndexes[repoURL+" "+check] = ci
+		}
+
+		testCase := &suite.TestCases[ci]
+
+		switch result.resultType {
+		case RESULT_ERROR:
+
+			testCase.failures++
+
+			if testCase.Failure == nil {
+				testCase.Failure = &junitFailure{
+					Message: result.String(),
+					Type:    result.rule,
+					Content: result.String(),
+				}
+			} else {
+				testCase.Failure.Message = fmt.Sprintf("%d policy failures", testCase.failures)
+				testCase.Failure.Type = check
+				testCase.Failure.Content += "\n" + result.String()
+			}
+		case RESULT_INFO, RESULT_WARNING, RESULT_WAIVED, RESULT_BASELINED, RESULT_FIXED:
+			testCase.SystemOut += fmt.Sprintf("%s: %s\n", result.resultType, result)
+		}
+	}
+
+	for i := range suites.Suites {
+
+		suite := &suites.Suites[i]
+		suite.Tests = len(suite.TestCases)
+
+		for _, testCase := range suite.TestCases {
+			if testCase.Failure != nil {
+				suite.Failures++
+			}
+		}
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
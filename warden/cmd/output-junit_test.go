@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/repowarden/cli/warden/vcsurl"
+)
+
+func TestJUnitReport(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	url, err := vcsurl.Parse("https://github.com/felicianotech/hugo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := WardenRepo(url, nil)
+
+	var results auditResults
+	results.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	results.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "wontfix")
+	results.add(repo, RESULT_WARNING, RULE_ACCESS_VISIBILITY, ERR_ACCESS_VISIBILITY)
+	results.add(repo, RESULT_DEBUG, RULE_ACCESS_VISIBILITY, ERR_ACCESS_VISIBILITY)
+	results.add(other, RESULT_PASS, "label", MSG_CHECK_PASSED, "label")
+
+	var b bytes.Buffer
+
+	if err := writeJUnitReport(&b, results); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(b.String(), xml.Header) {
+		t.Error("The report should start with the XML header.")
+	}
+
+	var suites junitTestSuites
+
+	if err := xml.Unmarshal(b.Bytes(), &suites); err != nil {
+		t.Fatalf("The report should be valid XML: %s", err)
+	}
+
+	if suites.Tests != 3 || suites.Failures != 1 || len(suites.Suites) != 2 {
+		t.Fatalf("Want 2 suites with 3 tests and 1 failure, got %d suites with %d tests and %d failures", len(suites.Suites), suites.Tests, suites.Failures)
+	}
+
+	suite := suites.Suites[0]
+
+	if suite.Name != "https://github.com/felicianotech/sonar" || suite.Tests != 2 || suite.Failures != 1 {
+		t.Errorf("The first suite wasn't built correctly, got %+v", suite)
+	}
+
+	label := suite.TestCases[0]
+
+	if label.Name != "label" || label.Failure == nil || label.Failure.Message != "2 policy failures" || label.Failure.Type != "label" {
+		t.Fatalf("Want both label failures in one test case, got %+v", label)
+	}
+
+	if label.Failure.Content != "The label 'bug' is missing.\nThe label 'wontfix' is missing." {
+		t.Errorf("Want each failure in the content, got '%s'", label.Failure.Content)
+	}
+
+	// warnings are output rather than failures and debug results are left out
+	access := suite.TestCases[1]
+
+	if access.Name != "access" || access.Failure != nil || access.SystemOut != "warning: "+ERR_ACCESS_VISIBILITY+"\n" {
+		t.Errorf("Want the warning as output of the access test case, got %+v", access)
+	}
+
+	passed := suites.Suites[1]
+
+	if passed.Failures != 0 || len(passed.TestCases) != 1 || passed.TestCases[0].Failure != nil {
+		t.Errorf("Want a passing test case for the second repository, got %+v", passed)
+	}
+}
+
+func TestJUnitReportSingleFailure(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	var results auditResults
+	results.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+
+	var b bytes.Buffer
+
+	if err := writeJUnitReport(&b, results); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+
+	if err := xml.Unmarshal(b.Bytes(), &suites); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := suites.Suites[0].TestCases[0].Failure
+
+	if failure == nil || failure.Type != RULE_LABEL_MISSING || failure.Message != "The label 'bug' is missing." {
+		t.Errorf("Want the failure to use the result's rule and message, got %+v", failure)
+	}
+}
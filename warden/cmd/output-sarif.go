@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	SARIF_SCHEMA  = "https://json.schemastore.org/sarif-2.1.0.json"
+	SARIF_VERSION = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string                  `json:"id"`
+	ShortDescription sarifMessage            `json:"shortDescription"`
+	MessageStrings   map[string]sarifMessage `json:"messageStrings,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+	ID   string `json:"id,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID        string             `json:"ruleId"`
+	RuleIndex     int                `json:"ruleIndex"`
+	BaselineState string             `json:"baselineState,omitempty"`
+	Level         string             `json:"level"`
+	Message       sarifMessage       `json:"message"`
+	Locations     []sarifLocation    `json:"locations"`
+	Suppressions  []sarifSuppression `json:"suppressions,omitempty"`
+	Properties    map[string]string  `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Converts a printf style message into a SARIF message string, where
+// placeholders are numbered such as {0}.
+func sarifMessageString(message string) string {
+
+	var b strings.Builder
+	n := 0
+
+	for i := 0; i < len(message); i++ {
+
+		if message[i] == '%' && i+1 < len(message) {
+
+			i++
+			if message[i] == '%' {
+				b.WriteByte('%')
+				continue
+			}
+
+			fmt.Fprintf(&b, "{%d}", n)
+			n++
+			continue
+		}
+
+		b.WriteByte(message[i])
+	}
+
+	return b.String()
+}
+
+// Returns the SARIF level for a result type
+func sarifLevel(resultType auditResultType) string {
+
+	switch resultType {
+	case RESULT_ERROR:
+		return "error"
+	case RESULT_WARNING:
+		return "warning"
+	case RESULT_FIXED:
+		return "none"
+	}
+
+	return "note"
+}
+
+// Returns the SARIF baseline state of a result. Results from audits that
+// didn't use a baseline have no state.
+func sarifBaselineState(resultType auditResultType, usedBaseline bool) string {
+
+	switch {
+	case !usedBaseline:
+		return ""
+	case resultType == RESULT_BASELINED:
+		return "unchanged"
+	case resultType == RESULT_FIXED:
+		return "absent"
+	}
+
+	return "new"
+}
+
+// Writes the results as a SARIF 2.1.0 log. Results are given a baseline state
+// when the audit used a baseline file.
+func writeSARIFReport(w io.Writer, results auditResults, usedBaseline bool) error {
+
+	var rules []sarifRule
+	ruleIndexes := make(map[string]int)
+
+	// each registered check becomes a SARIF rule
+	for i, check := range checks {
+
+		messages := make(map[string]sarifMessage)
+
+		for rule, message := range ruleMessages {
+			if ruleCheck(rule) == check.ID() {
+				messages[strings.TrimPrefix(rule, check.ID()+".")] = sarifMessage{Text: sarifMessageString(message)}
+			}
+		}
+
+		rules = append(rules, sarifRule{
+			ID:               check.ID(),
+			ShortDescription: sarifMessage{Text: check.Description()},
+			MessageStrings:   messages,
+		})
+		ruleIndexes[check.ID()] = i
+	}
+
+	sarifResults := []sarifResult{}
+	for _, result := range results {
+
+		if result.resultType == RESULT_DEBUG || result.resultType == RESULT_PASS {
+			continue
+		}
+
+		check := ruleCheck(result.rule)
+
+		index, ok := ruleIndexes[check]
+		if !ok {
+			return fmt.Errorf("The rule '%s' doesn't belong to a known check.", result.rule)
+		}
+
+		var suppressions []sarifSuppression
+
+		if result.waiver != nil {
+			suppressions = append(suppressions, sarifSuppression{
+				Kind:          "external",
+				Justification: fmt.Sprintf("%s (approved by %s until %s)", result.waiver.Justification, result.waiver.Approver, result.waiver.Expires),
+			})
+		}
+
+		sarifResults = append(sarifResults, sarifResult{
+			RuleID:        check,
+			RuleIndex:     index,
+			BaselineState: sarifBaselineState(result.resultType, usedBaseline),
+			Level:         sarifLevel(result.resultType),
+			Message: sarifMessage{
+				Text: result.String(),
+				ID:   strings.TrimPrefix(result.rule, check+"."),
+			},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: result.repository.ToHTTPS()},
+				},
+			}},
+			Suppressions: suppressions,
+			Properties:   map[string]string{"rule": result.rule},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sarifLog{
+		Schema:  SARIF_SCHEMA,
+		Version: SARIF_VERSION,
+		Runs: []sarifRun{{
+			Tool: sarifTool{
+				Driver: sarifDriver{
+					Name:           "Warden",
+					Version:        version,
+					InformationURI: "https://github.com/repowarden/cli",
+					Rules:          rules,
+				},
+			},
+			Results: sarifResults,
+		}},
+	})
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSARIFMessageString(t *testing.T) {
+
+	tcs := []struct {
+		message string
+		want    string
+	}{
+		{message: "The label '%s' is missing.", want: "The label '{0}' is missing."},
+		{message: "'%s' should be '%s', not %d.", want: "'{0}' should be '{1}', not {2}."},
+		{message: "100%% done", want: "100% done"},
+		{message: "No placeholders", want: "No placeholders"},
+	}
+
+	for _, tc := range tcs {
+		if got := sarifMessageString(tc.message); got != tc.want {
+			t.Errorf("For '%s', want '%s', got '%s'", tc.message, tc.want, got)
+		}
+	}
+}
+
+func TestSARIFReport(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	var results auditResults
+	results.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	results.add(repo, RESULT_WARNING, RULE_ACCESS_VISIBILITY, ERR_ACCESS_VISIBILITY)
+	results.add(repo, RESULT_PASS, "branch", MSG_CHECK_PASSED, "branch")
+	results.add(repo, RESULT_WAIVED, RULE_LICENSE_MISSING, ERR_LICENSE_MISSING)
+	results[3].waiver = &waiver{Rule: RULE_LICENSE_MISSING, Justification: "Archived soon", Approver: "felicianotech", Expires: "2026-06-15"}
+
+	var b bytes.Buffer
+
+	if err := writeSARIFReport(&b, results, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+
+	if err := json.Unmarshal(b.Bytes(), &log); err != nil {
+		t.Fatalf("The report should be valid JSON: %s", err)
+	}
+
+	if log.Schema != SARIF_SCHEMA || log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Want a single SARIF 2.1.0 run, got %+v", log)
+	}
+
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != len(checks) {
+		t.Errorf("Want a SARIF rule for each of the %d checks, got %d", len(checks), len(run.Tool.Driver.Rules))
+	}
+
+	// passes aren't included
+	if len(run.Results) != 3 {
+		t.Fatalf("Want 3 results, got %d", len(run.Results))
+	}
+
+	for _, result := range run.Results {
+
+		rule := run.Tool.Driver.Rules[result.RuleIndex]
+
+		if rule.ID != result.RuleID {
+			t.Errorf("The result for '%s' points to the rule '%s'.", result.RuleID, rule.ID)
+		}
+
+		if _, ok := rule.MessageStrings[result.Message.ID]; !ok {
+			t.Errorf("The rule '%s' has no message string '%s'.", rule.ID, result.Message.ID)
+		}
+
+		if len(result.Locations) != 1 || result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "https://github.com/felicianotech/sonar" {
+			t.Errorf("The result for '%s' doesn't point to the repository, got %+v", result.RuleID, result.Locations)
+		}
+
+		if result.BaselineState != "" {
+			t.Errorf("Results without a baseline shouldn't have a baseline state, got '%s'", result.BaselineState)
+		}
+	}
+
+	label := run.Results[0]
+
+	if label.RuleID != "label" || label.Level != "error" || label.Message.ID != "missing" || label.Message.Text != "The label 'bug' is missing." || label.Properties["rule"] != RULE_LABEL_MISSING {
+		t.Errorf("The label result wasn't converted correctly, got %+v", label)
+	}
+
+	if run.Results[1].Level != "warning" {
+		t.Errorf("Want the warning level, got '%s'", run.Results[1].Level)
+	}
+
+	waived := run.Results[2]
+
+	if waived.Level != "note" || len(waived.Suppressions) != 1 || waived.Suppressions[0].Kind != "external" {
+		t.Errorf("Want the waived result to be an external suppression, got %+v", waived)
+	}
+}
+
+func TestSARIFReportBaseline(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	var results auditResults
+	results.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	results.add(repo, RESULT_BASELINED, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "wontfix")
+	results.add(repo, RESULT_FIXED, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "high-priority")
+
+	// every violation can be new, leaving nothing from the baseline
+	var newResults auditResults
+	newResults.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	newResults.add(repo, RESULT_WARNING, RULE_ACCESS_VISIBILITY, ERR_ACCESS_VISIBILITY)
+
+	tcs := []struct {
+		results auditResults
+		want    []string
+	}{
+		{results: results, want: []string{"new", "unchanged", "absent"}},
+		{results: newResults, want: []string{"new", "new"}},
+	}
+
+	for i, tc := range tcs {
+
+		var b bytes.Buffer
+
+		if err := writeSARIFReport(&b, tc.results, true); err != nil {
+			t.Fatal(err)
+		}
+
+		var log sarifLog
+
+		if err := json.Unmarshal(b.Bytes(), &log); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(log.Runs[0].Results) != len(tc.want) {
+			t.Fatalf("Case %d: Want %d results, got %d", i+1, len(tc.want), len(log.Runs[0].Results))
+		}
+
+		for j, result := range log.Runs[0].Results {
+			if result.BaselineState != tc.want[j] {
+				t.Errorf("Case %d, result %d: Want the baseline state '%s', got '%s'", i+1, j+1, tc.want[j], result.BaselineState)
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// The formats `warden audit` can output results in
+var outputFormats = []string{"text", "json", "sarif", "junit"}
+
+// The summary counts included at the top of every report
+type auditSummary struct {
+	Group        string `json:"group"`
+	Repositories int    `json:"repositories"`
+	Errors       int    `json:"errors"`
+	Warnings     int    `json:"warnings"`
+	Infos        int    `json:"infos"`
+	Waived       int    `json:"waived"`
+	Baselined    int    `json:"baselined"`
+	Fixed        int    `json:"fixed"`
+	Passes       int    `json:"passes"`
+
+	Expirations []accessExpiration `json:"expirations"` // upcoming, soonest first
+}
+
+// Access that's about to expire on a repository
+type accessExpiration struct {
+	Repository string `json:"repository"`
+	User       string `json:"user"`
+	Expires    string `json:"expires"`
+}
+
+// Builds the summary for a set of results
+func summarize(results auditResults, repoCount int, group string) auditSummary {
+
+	return auditSummary{
+		Group:        group,
+		Repositories: repoCount,
+		Errors:       len(results.ByType(RESULT_ERROR)),
+		Warnings:     len(results.ByType(RESULT_WARNING)),
+		Infos:        len(results.ByType(RESULT_INFO)),
+		Waived:       len(results.ByType(RESULT_WAIVED)),
+		Baselined:    len(results.ByType(RESULT_BASELINED)),
+		Fixed:        len(results.ByType(RESULT_FIXED)),
+		Passes:       len(results.ByType(RESULT_PASS)),
+		Expirations:  upcomingExpirations(results),
+	}
+}
+
+// Collects the access expiring soon across every repository. Fixed results
+// are for access that's already gone.
+func upcomingExpirations(results auditResults) []accessExpiration {
+
+	expirations := []accessExpiration{}
+
+	for _, result := range results {
+
+		switch result.resultType {
+		case RESULT_WARNING, RESULT_ERROR, RESULT_WAIVED, RESULT_BASELINED:
+		default:
+			continue
+		}
+
+		if result.rule == RULE_ACCESS_EXPIRING {
+			expirations = append(expirations, accessExpiration{
+				result.repository.ToHTTPS(),
+				result.values[0].(string),
+				result.values[1].(string),
+			})
+		}
+	}
+
+	// the dates sort as strings
+	sort.SliceStable(expirations, func(i, j int) bool {
+		return expirations[i].Expires < expirations[j].Expires
+	})
+
+	return expirations
+}
+
+// Prints the human readable report. Errors go to stderr while everything else
+// goes to stdout.
+func writeTextReport(results auditResults, repoCount int, group string) {
+
+	summary := summarize(results, repoCount, group)
+
+	fmt.Printf(
+		`======================================================================
+                         Warden Audit Results
+
+  errors: %d     warnings: %d     waived: %d     repos: %d     group: %s
+======================================================================
+
+`,
+		summary.Errors,
+		summary.Warnings,
+		summary.Waived,
+		summary.Repositories,
+		summary.Group,
+	)
+
+	if len(results) > summary.Passes {
+
+		var curRepo string
+
+		for _, result := range results {
+
+			// passing checks and debug results are only included in the other
+			// formats
+			if result.resultType == RESULT_PASS || result.resultType == RESULT_DEBUG {
+				continue
+			}
+
+			// print repo URL whenever we move to the next one
+			if curRepo != result.repository.ToHTTPS() {
+
+				curRepo = result.repository.ToHTTPS()
+				fmt.Fprintf(os.Stderr, "%s:\n", curRepo)
+			}
+
+			switch result.resultType {
+			case RESULT_ERROR:
+				fmt.Fprintf(os.Stderr, "  \033[31mx\033[0m %s\n", result)
+			case RESULT_WARNING:
+				fmt.Printf("  \033[33mo\033[0m %s\n", result)
+			case RESULT_INFO:
+				fmt.Printf("  \033[36mi\033[0m %s\n", result)
+			case RESULT_WAIVED:
+				fmt.Printf("  \033[34mw\033[0m %s (waived by %s until %s: %s)\n", result, result.waiver.Approver, result.waiver.Expires, result.waiver.Justification)
+			case RESULT_BASELINED:
+				fmt.Printf("  \033[90mb\033[0m %s (in the baseline)\n", result)
+			case RESULT_FIXED:
+				fmt.Printf("  \033[32m+\033[0m %s (fixed since the baseline)\n", result)
+			}
+		}
+
+		fmt.Println("") // intentional
+	}
+
+	if len(summary.Expirations) > 0 {
+
+		fmt.Println("Upcoming access expirations:")
+
+		for _, expiration := range summary.Expirations {
+			fmt.Printf("  %s  %s  %s\n", expiration.Expires, expiration.User, expiration.Repository)
+		}
+
+		fmt.Println("") // intentional
+	}
+}
+
+// Writes the results and summary as a single JSON document
+func writeJSONReport(w io.Writer, results auditResults, repoCount int, group string) error {
+
+	if results == nil {
+		results = auditResults{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(struct {
+		Summary auditSummary `json:"summary"`
+		Results auditResults `json:"results"`
+	}{
+		summarize(results, repoCount, group),
+		results,
+	})
+}
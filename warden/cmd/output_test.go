@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestJSONReport(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	var results auditResults
+	results.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	results.add(repo, RESULT_WARNING, RULE_ACCESS_VISIBILITY, ERR_ACCESS_VISIBILITY)
+	results.add(repo, RESULT_PASS, "branch", MSG_CHECK_PASSED, "branch")
+
+	var b bytes.Buffer
+
+	if err := writeJSONReport(&b, results, 3, "all"); err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Summary auditSummary `json:"summary"`
+		Results []struct {
+			Repository string `json:"repository"`
+			Type       string `json:"type"`
+			Rule       string `json:"rule"`
+			Message    string `json:"message"`
+			Values     []any  `json:"values"`
+		} `json:"results"`
+	}
+
+	if err := json.Unmarshal(b.Bytes(), &report); err != nil {
+		t.Fatalf("The report should be valid JSON: %s", err)
+	}
+
+	if report.Summary.Group != "all" || report.Summary.Repositories != 3 || report.Summary.Errors != 1 || report.Summary.Warnings != 1 || report.Summary.Passes != 1 {
+		t.Errorf("The summary doesn't match the results, got %+v", report.Summary)
+	}
+
+	if len(report.Results) != 3 {
+		t.Fatalf("Want 3 results, got %d", len(report.Results))
+	}
+
+	first := report.Results[0]
+
+	if first.Repository != "https://github.com/felicianotech/sonar" || first.Type != "error" || first.Rule != RULE_LABEL_MISSING || first.Message != "The label 'bug' is missing." {
+		t.Errorf("The first result wasn't encoded correctly, got %+v", first)
+	}
+
+	if len(first.Values) != 1 || first.Values[0] != "bug" {
+		t.Errorf("Want the values [bug], got %v", first.Values)
+	}
+
+	// results without values still have an array
+	if report.Results[1].Values == nil {
+		t.Error("Results without values should have an empty values array.")
+	}
+}
+
+func TestJSONReportEmpty(t *testing.T) {
+
+	var b bytes.Buffer
+
+	if err := writeJSONReport(&b, nil, 0, "all"); err != nil {
+		t.Fatal(err)
+	}
+
+	var report map[string]json.RawMessage
+
+	if err := json.Unmarshal(b.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(report["results"]) != "[]" {
+		t.Errorf("Want an empty results array, got %s", report["results"])
+	}
+}
+
+func TestUpcomingExpirations(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	var results auditResults
+	results.add(repo, RESULT_WARNING, RULE_ACCESS_EXPIRING, ERR_ACCESS_EXPIRING, "contractor", "2026-06-20", 10)
+	results.add(repo, RESULT_BASELINED, RULE_ACCESS_EXPIRING, ERR_ACCESS_EXPIRING, "responder", "2026-06-12", 2)
+	results.add(repo, RESULT_WARNING, RULE_ACCESS_VISIBILITY, ERR_ACCESS_VISIBILITY)
+
+	// access that was already removed comes back from the baseline as fixed
+	results.add(repo, RESULT_FIXED, RULE_ACCESS_EXPIRING, "The access for 'intern' expires on 2026-06-11, 1 days from now.")
+
+	expirations := upcomingExpirations(results)
+
+	want := []accessExpiration{
+		{"https://github.com/felicianotech/sonar", "responder", "2026-06-12"},
+		{"https://github.com/felicianotech/sonar", "contractor", "2026-06-20"},
+	}
+
+	if !slices.Equal(expirations, want) {
+		t.Errorf("Want the expirations %v, got %v", want, expirations)
+	}
+}
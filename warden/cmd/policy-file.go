@@ -6,17 +6,20 @@ package cmd
 
 // The top-level structure representing a policy.yml file.
 type PolicyFile struct {
-	DefaultBranch string             `yaml:"defaultBranch"`
-	Archived      bool               `yaml:"archived"` // include archived repos in lookup?
-	License       *licensePolicy     `yaml:"license"`
-	Labels        []string           `yaml:"labels"`
-	LabelStrategy string             `yaml:"labelStrategy"`
-	Access        []accessPolicy     `yaml:"access"`
-	Codeowners    []codeownersPolicy `yaml:"codeowners"`
-}
-
-// Which code licenses to allow and for which scope
-type licensePolicy struct {
-	Scope string   `yaml:"scope"`
-	Names []string `yaml:"names"`
+	DefaultBranch    string                   `yaml:"defaultBranch"`
+	Archived         bool                     `yaml:"archived"` // include archived repos in lookup?
+	License          *licensePolicy           `yaml:"license"`
+	Labels           []string                 `yaml:"labels"`
+	LabelStrategy    string                   `yaml:"labelStrategy"`
+	Access           []accessPolicy           `yaml:"access"`
+	Collaborators    []collaboratorsPolicy    `yaml:"collaborators"`
+	Codeowners       []codeownersPolicy       `yaml:"codeowners"`
+	BranchProtection []branchProtectionPolicy `yaml:"branchProtection"`
+	Rulesets         []rulesetPolicy          `yaml:"rulesets"`
+	Settings         []settingsPolicy         `yaml:"settings"`
+	Security         []securityPolicy         `yaml:"security"`
+	Alerts           []alertPolicy            `yaml:"alerts"`
+	Files            []filesPolicy            `yaml:"files"`
+	ManagedFiles     []managedFilePolicy      `yaml:"managedFiles"`
+	Rules            map[string]rulePolicy    `yaml:"rules"` // severity overrides by rule ID
 }
@@ -8,8 +8,9 @@ import (
 )
 
 type RepositoryDefinition struct {
-	URL  string   `yaml:"url"`
-	Tags []string `yaml:"tags,omitempty"`
+	URL   string   `yaml:"url"`
+	Tags  []string `yaml:"tags,omitempty"`
+	Group string   `yaml:"-"` // the group the repo is listed under, set by GetRepositories
 }
 
 // =============================================================================
@@ -54,7 +55,12 @@ func (this *RepositoryGroup) ListRepositories(listChildren bool) []string {
 // include children or not.
 func (this *RepositoryGroup) GetRepositories(listChildren bool) []RepositoryDefinition {
 
-	repos := this.Repositories
+	var repos []RepositoryDefinition
+
+	for _, repo := range this.Repositories {
+		repo.Group = this.Group
+		repos = append(repos, repo)
+	}
 
 	if listChildren {
 		for _, group := range this.Children {
@@ -5,7 +5,8 @@ import "github.com/repowarden/cli/warden/vcsurl"
 // There are other Repository types scattered around the codebase, but this should be the main when dealing with the core business logic.
 type wardenRepo struct {
 	*vcsurl.Repository
-	tags []string
+	tags  []string
+	group string
 }
 
 // Returns a string slice of tags. Generated tags such as org are injected into the response.
@@ -13,12 +14,18 @@ func (this *wardenRepo) Tags() []string {
 	return append(this.tags, this.Owner)
 }
 
+// Returns the name of the group the repo is listed under in the repositories
+// file, if known
+func (this *wardenRepo) Group() string {
+	return this.group
+}
+
 // Create a new WardenRepo
 func WardenRepo(repo *vcsurl.Repository, tags []string) *wardenRepo {
 
 	return &wardenRepo{
-		repo,
-		tags,
+		Repository: repo,
+		tags:       tags,
 	}
 }
 
@@ -34,10 +41,10 @@ func WardenRepos(repoDefs []RepositoryDefinition) ([]*wardenRepo, error) {
 			return nil, err
 		}
 
-		repos = append(repos, WardenRepo(
-			repo,
-			repoDef.Tags,
-		))
+		wRepo := WardenRepo(repo, repoDef.Tags)
+		wRepo.group = repoDef.Group
+
+		repos = append(repos, wRepo)
 	}
 
 	return repos, nil
@@ -1,6 +1,9 @@
 package cmd
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // an enum for what an auditResult can be
 type auditResultType int
@@ -10,14 +13,45 @@ const (
 	RESULT_INFO
 	RESULT_WARNING
 	RESULT_ERROR
+	RESULT_PASS      // a check was evaluated and nothing was wrong
+	RESULT_WAIVED    // a failure covered by an active waiver
+	RESULT_BASELINED // a failure that's already in the baseline
+	RESULT_FIXED     // a failure in the baseline that no longer happens
 )
 
+// Returns the lowercase name of the result type, as used in output formats
+func (this auditResultType) String() string {
+
+	switch this {
+	case RESULT_DEBUG:
+		return "debug"
+	case RESULT_INFO:
+		return "info"
+	case RESULT_WARNING:
+		return "warning"
+	case RESULT_ERROR:
+		return "error"
+	case RESULT_PASS:
+		return "pass"
+	case RESULT_WAIVED:
+		return "waived"
+	case RESULT_BASELINED:
+		return "baselined"
+	case RESULT_FIXED:
+		return "fixed"
+	}
+
+	return "unknown"
+}
+
 // auditResults are what a policy audit can return. These can be notes, debug information, warnings, and most importantly, errors.
 type auditResult struct {
 	repository *wardenRepo
 	resultType auditResultType
+	rule       string
 	message    string
 	values     []any
+	waiver     *waiver // set when the result is waived
 }
 
 // Properly print out a result
@@ -25,19 +59,72 @@ func (this auditResult) String() string {
 	return fmt.Sprintf(this.message, this.values...)
 }
 
+// Marshals a result for the JSON output format
+func (this auditResult) MarshalJSON() ([]byte, error) {
+
+	values := this.values
+	if values == nil {
+		values = []any{}
+	}
+
+	return json.Marshal(struct {
+		Repository string  `json:"repository"`
+		Type       string  `json:"type"`
+		Rule       string  `json:"rule"`
+		Message    string  `json:"message"`
+		Values     []any   `json:"values"`
+		Waiver     *waiver `json:"waiver,omitempty"`
+	}{
+		this.repository.ToHTTPS(),
+		this.resultType.String(),
+		this.rule,
+		this.String(),
+		values,
+		this.waiver,
+	})
+}
+
 // the list of results returned from the audit
 type auditResults []auditResult
 
 // adds a new result
-func (this *auditResults) add(repo *wardenRepo, resultType auditResultType, message string, values ...any) {
+func (this *auditResults) add(repo *wardenRepo, resultType auditResultType, rule, message string, values ...any) {
 	*this = append(*this, auditResult{
 		repo,
 		resultType,
+		rule,
 		message,
 		values,
+		nil,
 	})
 }
 
+// records a pass for each check evaluated against a repo that didn't produce
+// any warnings or errors. Notes and debug information don't stop a pass.
+func (this *auditResults) addPasses(repo *wardenRepo, checks []string) {
+
+	for _, check := range checks {
+
+		failed := false
+
+		for _, result := range *this {
+
+			if result.resultType == RESULT_INFO || result.resultType == RESULT_DEBUG {
+				continue
+			}
+
+			if result.repository == repo && ruleCheck(result.rule) == check {
+				failed = true
+				break
+			}
+		}
+
+		if !failed {
+			this.add(repo, RESULT_PASS, check, MSG_CHECK_PASSED, check)
+		}
+	}
+}
+
 // Returns a subset, just the one type
 func (this *auditResults) ByType(resultType auditResultType) auditResults {
 
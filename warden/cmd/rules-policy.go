@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Overrides for how a single rule is reported
+type rulePolicy struct {
+	Severity string `yaml:"severity"`
+}
+
+// The severities a rule can be set to. 'off' drops the rule's results.
+var ruleSeverities = map[string]auditResultType{
+	"error":   RESULT_ERROR,
+	"warning": RESULT_WARNING,
+	"info":    RESULT_INFO,
+}
+
+// Makes sure every rule override refers to a known rule and severity
+func (this *PolicyFile) validateRules() error {
+
+	for rule, override := range this.Rules {
+
+		if _, ok := ruleMessages[rule]; !ok {
+			return fmt.Errorf("The policy sets '%s' but that isn't a known rule. Rules are: %s", rule, strings.Join(knownRules(), ", "))
+		}
+
+		if _, ok := ruleSeverities[override.Severity]; !ok && override.Severity != "off" {
+			return fmt.Errorf("The severity '%s' for the rule '%s' isn't valid. Options are: error, warning, info, off", override.Severity, rule)
+		}
+	}
+
+	return nil
+}
+
+// Returns every rule ID, sorted
+func knownRules() []string {
+
+	var rules []string
+
+	for rule := range ruleMessages {
+		rules = append(rules, rule)
+	}
+
+	sort.Strings(rules)
+
+	return rules
+}
+
+// Applies the policy's severity overrides to results. Passing results are
+// left alone.
+func applyRulePolicies(results auditResults, rules map[string]rulePolicy) auditResults {
+
+	var applied auditResults
+
+	for _, result := range results {
+
+		override, ok := rules[result.rule]
+		if !ok || result.resultType == RESULT_PASS {
+			applied = append(applied, result)
+			continue
+		}
+
+		if override.Severity == "off" {
+			continue
+		}
+
+		result.resultType = ruleSeverities[override.Severity]
+		applied = append(applied, result)
+	}
+
+	return applied
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestApplyRulePolicies(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	var results auditResults
+	results.add(repo, RESULT_ERROR, RULE_LICENSE_MISSING, ERR_LICENSE_MISSING)
+	results.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+	results.add(repo, RESULT_ERROR, RULE_BRANCH_DEFAULT, ERR_BRANCH_DEFAULT, "trunk", "main")
+
+	applied := applyRulePolicies(results, map[string]rulePolicy{
+		RULE_LICENSE_MISSING: {Severity: "warning"},
+		RULE_LABEL_MISSING:   {Severity: "off"},
+	})
+
+	if !slices.Equal(resultRules(applied), []string{RULE_LICENSE_MISSING, RULE_BRANCH_DEFAULT}) {
+		t.Fatalf("Want the label result dropped, got %v", resultRules(applied))
+	}
+
+	if applied[0].resultType != RESULT_WARNING {
+		t.Errorf("Want '%s' to be a warning, got %s", RULE_LICENSE_MISSING, applied[0].resultType)
+	}
+
+	if applied[1].resultType != RESULT_ERROR {
+		t.Errorf("Want '%s' to stay an error, got %s", RULE_BRANCH_DEFAULT, applied[1].resultType)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+
+	tcs := []struct {
+		rules map[string]rulePolicy
+		valid bool
+	}{
+		{rules: map[string]rulePolicy{RULE_ACCESS_EXTRA: {Severity: "info"}}, valid: true},
+		{rules: map[string]rulePolicy{RULE_ACCESS_EXTRA: {Severity: "off"}}, valid: true},
+		{rules: map[string]rulePolicy{RULE_ACCESS_EXTRA: {Severity: "fatal"}}, valid: false},
+		{rules: map[string]rulePolicy{"access.unknown": {Severity: "error"}}, valid: false},
+	}
+
+	for i, tc := range tcs {
+
+		policy := &PolicyFile{Rules: tc.rules}
+
+		if err := policy.validateRules(); (err == nil) != tc.valid {
+			t.Errorf("Case %d: Want valid to be %t, got error %v", i+1, tc.valid, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import "golang.org/x/exp/slices"
+
+// A ruleset, or set of rules, that should apply to the audited branch
+type rulesetPolicy struct {
+	Name  string        `yaml:"name"` // a ruleset with this name needs to target the branch
+	Rules []rulesetRule `yaml:"rules"`
+	Tags  []string      `yaml:"tags"`
+}
+
+// A rule that should be in effect, by type. Parameters that are left out
+// aren't checked.
+type rulesetRule struct {
+	Type                             string   `yaml:"type"`
+	RequiredApprovingReviewCount     *int     `yaml:"requiredApprovingReviewCount"`
+	DismissStaleReviewsOnPush        *bool    `yaml:"dismissStaleReviewsOnPush"`
+	RequireCodeOwnerReview           *bool    `yaml:"requireCodeOwnerReview"`
+	RequireLastPushApproval          *bool    `yaml:"requireLastPushApproval"`
+	RequiredReviewThreadResolution   *bool    `yaml:"requiredReviewThreadResolution"`
+	RequiredStatusChecks             []string `yaml:"requiredStatusChecks"`
+	StrictRequiredStatusChecksPolicy *bool    `yaml:"strictRequiredStatusChecksPolicy"`
+}
+
+// A rule in effect on a branch, from a repository or organization ruleset
+type effectiveRule struct {
+	Type          string         `json:"type"`
+	Parameters    ruleParameters `json:"parameters"`
+	RulesetID     int64          `json:"ruleset_id"`
+	RulesetSource string         `json:"ruleset_source"`
+}
+
+// The parameters of the rule types Warden understands
+type ruleParameters struct {
+	DismissStaleReviewsOnPush      bool `json:"dismiss_stale_reviews_on_push"`
+	RequireCodeOwnerReview         bool `json:"require_code_owner_review"`
+	RequireLastPushApproval        bool `json:"require_last_push_approval"`
+	RequiredApprovingReviewCount   int  `json:"required_approving_review_count"`
+	RequiredReviewThreadResolution bool `json:"required_review_thread_resolution"`
+	RequiredStatusChecks           []struct {
+		Context string `json:"context"`
+	} `json:"required_status_checks"`
+	StrictRequiredStatusChecksPolicy bool `json:"strict_required_status_checks_policy"`
+}
+
+// A repository or organization ruleset
+type ruleset struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// Checks the rulesets in effect on the audited branch
+type rulesetCheck struct{}
+
+func init() {
+	registerCheck(rulesetCheck{})
+}
+
+func (this rulesetCheck) ID() string {
+	return "ruleset"
+}
+
+func (this rulesetCheck) Description() string {
+	return "The repository's rulesets apply the rules the policy requires to the branch."
+}
+
+func (this rulesetCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, rsPolicy := range policy.Rulesets {
+		if tagsMatched(rsPolicy.Tags, snapshot.repo.Tags()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this rulesetCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	rules, err := snapshot.EffectiveRules(snapshot.branch)
+	if isForbidden(err) || isNotFound(err) {
+		results.add(snapshot.repo, RESULT_WARNING, RULE_RULESET_VISIBILITY, ERR_RULESET_VISIBILITY, snapshot.branch)
+		return results, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, rsPolicy := range policy.Rulesets {
+
+		if !tagsMatched(rsPolicy.Tags, snapshot.repo.Tags()) {
+			continue
+		}
+
+		var named []*ruleset
+
+		if rsPolicy.Name != "" {
+
+			named, err = snapshot.Rulesets()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		results.merge(auditRulesetPolicy(rsPolicy, snapshot.repo, snapshot.branch, rules, named))
+	}
+
+	return results, nil
+}
+
+// Compares the rules in effect on a branch to the policy. Rulesets are only
+// needed when the policy names one.
+func auditRulesetPolicy(policy rulesetPolicy, repo *wardenRepo, branch string, rules []*effectiveRule, rulesets []*ruleset) auditResults {
+
+	var results auditResults
+
+	if policy.Name != "" {
+
+		found := false
+
+		for _, rs := range rulesets {
+
+			if rs.Name != policy.Name {
+				continue
+			}
+
+			for _, rule := range rules {
+				if rule.RulesetID == rs.ID {
+					found = true
+				}
+			}
+		}
+
+		if !found {
+			results.add(repo, RESULT_ERROR, RULE_RULESET_MISSING, ERR_RULESET_MISSING, policy.Name, branch)
+		}
+	}
+
+	for _, want := range policy.Rules {
+
+		var matching []*effectiveRule
+
+		for _, rule := range rules {
+			if rule.Type == want.Type {
+				matching = append(matching, rule)
+			}
+		}
+
+		if len(matching) == 0 {
+			results.add(repo, RESULT_ERROR, RULE_RULESET_RULE, ERR_RULESET_RULE, branch, want.Type)
+			continue
+		}
+
+		have := combineRuleParameters(matching)
+
+		weaker := func(parameter string, wantValue, haveValue any) {
+			results.add(repo, RESULT_ERROR, RULE_RULESET_WEAKER, ERR_RULESET_WEAKER, want.Type, branch, parameter, wantValue, haveValue)
+		}
+
+		if want.RequiredApprovingReviewCount != nil && have.RequiredApprovingReviewCount < *want.RequiredApprovingReviewCount {
+			weaker("requiredApprovingReviewCount", *want.RequiredApprovingReviewCount, have.RequiredApprovingReviewCount)
+		}
+
+		bools := []struct {
+			parameter string
+			want      *bool
+			have      bool
+		}{
+			{"dismissStaleReviewsOnPush", want.DismissStaleReviewsOnPush, have.DismissStaleReviewsOnPush},
+			{"requireCodeOwnerReview", want.RequireCodeOwnerReview, have.RequireCodeOwnerReview},
+			{"requireLastPushApproval", want.RequireLastPushApproval, have.RequireLastPushApproval},
+			{"requiredReviewThreadResolution", want.RequiredReviewThreadResolution, have.RequiredReviewThreadResolution},
+			{"strictRequiredStatusChecksPolicy", want.StrictRequiredStatusChecksPolicy, have.StrictRequiredStatusChecksPolicy},
+		}
+
+		// only turning a requirement off is weaker
+		for _, b := range bools {
+			if b.want != nil && *b.want && !b.have {
+				weaker(b.parameter, true, false)
+			}
+		}
+
+		var contexts []string
+		for _, check := range have.RequiredStatusChecks {
+			contexts = append(contexts, check.Context)
+		}
+
+		for _, context := range want.RequiredStatusChecks {
+			if !slices.Contains(contexts, context) {
+				results.add(repo, RESULT_ERROR, RULE_RULESET_CHECKS, ERR_RULESET_CHECKS, branch, context)
+			}
+		}
+	}
+
+	return results
+}
+
+// GitHub enforces the most restrictive version of a rule when more than one
+// ruleset applies, so parameters are combined the same way
+func combineRuleParameters(rules []*effectiveRule) ruleParameters {
+
+	var combined ruleParameters
+
+	for _, rule := range rules {
+
+		params := rule.Parameters
+
+		if params.RequiredApprovingReviewCount > combined.RequiredApprovingReviewCount {
+			combined.RequiredApprovingReviewCount = params.RequiredApprovingReviewCount
+		}
+
+		combined.DismissStaleReviewsOnPush = combined.DismissStaleReviewsOnPush || params.DismissStaleReviewsOnPush
+		combined.RequireCodeOwnerReview = combined.RequireCodeOwnerReview || params.RequireCodeOwnerReview
+		combined.RequireLastPushApproval = combined.RequireLastPushApproval || params.RequireLastPushApproval
+		combined.RequiredReviewThreadResolution = combined.RequiredReviewThreadResolution || params.RequiredReviewThreadResolution
+		combined.StrictRequiredStatusChecksPolicy = combined.StrictRequiredStatusChecksPolicy || params.StrictRequiredStatusChecksPolicy
+		combined.RequiredStatusChecks = append(combined.RequiredStatusChecks, params.RequiredStatusChecks...)
+	}
+
+	return combined
+}
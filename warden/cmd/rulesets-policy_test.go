@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestAuditRulesetPolicy(t *testing.T) {
+
+	repo := testSnapshot(t, nil, nil).repo
+
+	rulesets := []*ruleset{{ID: 1, Name: "org-default", Source: "felicianotech"}, {ID: 2, Name: "repo", Source: "felicianotech/sonar"}}
+
+	pullRequest := &effectiveRule{Type: "pull_request", RulesetID: 1}
+	pullRequest.Parameters.RequiredApprovingReviewCount = 1
+
+	// a repo ruleset making the org one stricter
+	stricter := &effectiveRule{Type: "pull_request", RulesetID: 2}
+	stricter.Parameters.RequiredApprovingReviewCount = 2
+	stricter.Parameters.RequireCodeOwnerReview = true
+
+	statusChecks := &effectiveRule{Type: "required_status_checks", RulesetID: 2}
+	statusChecks.Parameters.RequiredStatusChecks = append(statusChecks.Parameters.RequiredStatusChecks, struct {
+		Context string `json:"context"`
+	}{"test"})
+
+	policy := rulesetPolicy{
+		Name: "org-default",
+		Rules: []rulesetRule{
+			{Type: "pull_request", RequiredApprovingReviewCount: github.Int(2), RequireCodeOwnerReview: github.Bool(true)},
+			{Type: "required_status_checks", RequiredStatusChecks: []string{"test", "lint"}},
+			{Type: "non_fast_forward"},
+		},
+	}
+
+	tcs := []struct {
+		rules []*effectiveRule
+		want  []string
+	}{
+		{
+			rules: []*effectiveRule{pullRequest, stricter, statusChecks, {Type: "non_fast_forward", RulesetID: 1}},
+			want:  []string{RULE_RULESET_CHECKS},
+		},
+		{
+			rules: []*effectiveRule{pullRequest, statusChecks},
+			want:  []string{RULE_RULESET_WEAKER, RULE_RULESET_WEAKER, RULE_RULESET_CHECKS, RULE_RULESET_RULE},
+		},
+		{
+			rules: []*effectiveRule{stricter},
+			want:  []string{RULE_RULESET_MISSING, RULE_RULESET_RULE, RULE_RULESET_RULE},
+		},
+	}
+
+	for i, tc := range tcs {
+
+		results := auditRulesetPolicy(policy, repo, "trunk", tc.rules, rulesets)
+
+		if !slices.Equal(resultRules(results), tc.want) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.want, resultRules(results))
+		}
+	}
+}
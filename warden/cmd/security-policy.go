@@ -0,0 +1,112 @@
+package cmd
+
+import "errors"
+
+// The security features a repository needs to have enabled and for which
+// scope. Features that are left out or false aren't checked. When more than
+// one security policy applies to a repo, the features they require are
+// combined.
+type securityPolicy struct {
+	Scope                         string `yaml:"scope"` // 'public', 'private', 'internal', or 'all'
+	DependabotAlerts              bool   `yaml:"dependabotAlerts"`
+	DependabotSecurityUpdates     bool   `yaml:"dependabotSecurityUpdates"`
+	SecretScanning                bool   `yaml:"secretScanning"`
+	SecretScanningPushProtection  bool   `yaml:"secretScanningPushProtection"`
+	CodeScanningDefaultSetup      bool   `yaml:"codeScanningDefaultSetup"`
+	PrivateVulnerabilityReporting bool   `yaml:"privateVulnerabilityReporting"`
+}
+
+// The order security features are reported in
+var securityFeatureNames = []string{
+	"dependabotAlerts",
+	"dependabotSecurityUpdates",
+	"secretScanning",
+	"secretScanningPushProtection",
+	"codeScanningDefaultSetup",
+	"privateVulnerabilityReporting",
+}
+
+// Whether the policy applies to a repository with the given visibility
+func (this securityPolicy) applies(visibility string) bool {
+	return this.Scope == visibility || this.Scope == "all"
+}
+
+// Returns the names of the features this policy requires
+func (this securityPolicy) required() map[string]bool {
+
+	return map[string]bool{
+		"dependabotAlerts":              this.DependabotAlerts,
+		"dependabotSecurityUpdates":     this.DependabotSecurityUpdates,
+		"secretScanning":                this.SecretScanning,
+		"secretScanningPushProtection":  this.SecretScanningPushProtection,
+		"codeScanningDefaultSetup":      this.CodeScanningDefaultSetup,
+		"privateVulnerabilityReporting": this.PrivateVulnerabilityReporting,
+	}
+}
+
+// Checks that the repository's security features are enabled
+type securityCheck struct{}
+
+func init() {
+	registerCheck(securityCheck{})
+}
+
+func (this securityCheck) ID() string {
+	return "security"
+}
+
+func (this securityCheck) Description() string {
+	return "The security features the policy requires for the repository's visibility are enabled."
+}
+
+func (this securityCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, sPolicy := range policy.Security {
+		if sPolicy.applies(snapshot.data.GetVisibility()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this securityCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	required := make(map[string]bool)
+
+	for _, sPolicy := range policy.Security {
+
+		if !sPolicy.applies(snapshot.data.GetVisibility()) {
+			continue
+		}
+
+		for name, ok := range sPolicy.required() {
+			if ok {
+				required[name] = true
+			}
+		}
+	}
+
+	for _, name := range securityFeatureNames {
+
+		if !required[name] {
+			continue
+		}
+
+		enabled, err := snapshot.SecurityFeature(name)
+		if isForbidden(err) || isNotFound(err) || errors.Is(err, errSecurityNotReturned) || errors.Is(err, errSecurityNotAdmin) {
+			results.add(snapshot.repo, RESULT_WARNING, RULE_SECURITY_VISIBILITY, ERR_SECURITY_VISIBILITY, name)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if !enabled {
+			results.add(snapshot.repo, RESULT_ERROR, RULE_SECURITY_DISABLED, ERR_SECURITY_DISABLED, name)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestSecurityCheck(t *testing.T) {
+
+	policy := &PolicyFile{Security: []securityPolicy{
+		{Scope: "all", DependabotAlerts: true},
+		{Scope: "public", SecretScanning: true, PrivateVulnerabilityReporting: true},
+	}}
+
+	enabled := &github.SecurityAndAnalysis{SecretScanning: &github.SecretScanning{Status: github.String("enabled")}}
+	disabled := &github.SecurityAndAnalysis{SecretScanning: &github.SecretScanning{Status: github.String("disabled")}}
+
+	tcs := []struct {
+		data     *github.Repository
+		security map[string]bool
+		rules    []string
+	}{
+		{
+			data:     &github.Repository{Visibility: github.String("private")},
+			security: map[string]bool{"dependabotAlerts": true},
+			rules:    nil,
+		},
+		{
+			data:     &github.Repository{Visibility: github.String("public"), SecurityAndAnalysis: enabled},
+			security: map[string]bool{"dependabotAlerts": true, "privateVulnerabilityReporting": true},
+			rules:    nil,
+		},
+		{
+			data:     &github.Repository{Visibility: github.String("public"), SecurityAndAnalysis: disabled},
+			security: map[string]bool{"dependabotAlerts": false, "privateVulnerabilityReporting": true},
+			rules:    []string{RULE_SECURITY_DISABLED, RULE_SECURITY_DISABLED},
+		},
+		{
+			// security and analysis settings are only returned to admins
+			data:     &github.Repository{Visibility: github.String("public")},
+			security: map[string]bool{"dependabotAlerts": true, "privateVulnerabilityReporting": true},
+			rules:    []string{RULE_SECURITY_VISIBILITY},
+		},
+	}
+
+	for i, tc := range tcs {
+
+		snapshot := testSnapshot(t, nil, tc.data)
+		snapshot.security = tc.security
+
+		results, err := securityCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+
+	if (securityCheck{}).Applies(&PolicyFile{Security: []securityPolicy{{Scope: "public"}}}, testSnapshot(t, nil, nil)) {
+		t.Error("A public scope shouldn't apply to a repo of another visibility.")
+	}
+}
+
+func TestSecurityCheckUpdatesDisabled(t *testing.T) {
+
+	policy := &PolicyFile{Security: []securityPolicy{{Scope: "all", DependabotAlerts: true, DependabotSecurityUpdates: true}}}
+
+	tcs := []struct {
+		admin  bool
+		status int
+		rules  []string
+	}{
+		// with admin access a not found means the feature is off
+		{admin: true, status: http.StatusNotFound, rules: []string{RULE_SECURITY_DISABLED, RULE_SECURITY_DISABLED}},
+		{admin: true, status: http.StatusForbidden, rules: []string{RULE_SECURITY_VISIBILITY, RULE_SECURITY_VISIBILITY}},
+		// without it the features can't be seen
+		{admin: false, status: http.StatusNotFound, rules: []string{RULE_SECURITY_VISIBILITY, RULE_SECURITY_VISIBILITY}},
+	}
+
+	for i, tc := range tcs {
+
+		snapshot := testSnapshot(t, nil, &github.Repository{Permissions: map[string]bool{"admin": tc.admin}})
+		snapshot.client = testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+		})
+
+		results, err := securityCheck{}.Evaluate(policy, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+}
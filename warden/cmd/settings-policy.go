@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// Repository level settings. Settings that are left out aren't checked. When
+// more than one settings policy applies to a repo, later policies override
+// the settings of earlier ones.
+type settingsPolicy struct {
+	MergeMethods             []string `yaml:"mergeMethods"` // any of 'merge', 'squash', and 'rebase'
+	AllowAutoMerge           *bool    `yaml:"allowAutoMerge"`
+	DeleteBranchOnMerge      *bool    `yaml:"deleteBranchOnMerge"`
+	AllowUpdateBranch        *bool    `yaml:"allowUpdateBranch"`
+	SquashMergeCommitTitle   *string  `yaml:"squashMergeCommitTitle"`
+	HasIssues                *bool    `yaml:"hasIssues"`
+	HasWiki                  *bool    `yaml:"hasWiki"`
+	HasProjects              *bool    `yaml:"hasProjects"`
+	HasDiscussions           *bool    `yaml:"hasDiscussions"`
+	WebCommitSignoffRequired *bool    `yaml:"webCommitSignoffRequired"`
+	Tags                     []string `yaml:"tags"`
+}
+
+// The order settings are reported in
+var settingNames = []string{
+	"mergeMethods",
+	"allowAutoMerge",
+	"deleteBranchOnMerge",
+	"allowUpdateBranch",
+	"squashMergeCommitTitle",
+	"hasIssues",
+	"hasWiki",
+	"hasProjects",
+	"hasDiscussions",
+	"webCommitSignoffRequired",
+}
+
+// Returns the settings this policy sets, by name
+func (this settingsPolicy) values() map[string]any {
+
+	values := make(map[string]any)
+
+	if this.MergeMethods != nil {
+		methods := append([]string{}, this.MergeMethods...)
+		sort.Strings(methods)
+		values["mergeMethods"] = fmt.Sprint(methods)
+	}
+
+	pointers := map[string]any{
+		"allowAutoMerge":           this.AllowAutoMerge,
+		"deleteBranchOnMerge":      this.DeleteBranchOnMerge,
+		"allowUpdateBranch":        this.AllowUpdateBranch,
+		"squashMergeCommitTitle":   this.SquashMergeCommitTitle,
+		"hasIssues":                this.HasIssues,
+		"hasWiki":                  this.HasWiki,
+		"hasProjects":              this.HasProjects,
+		"hasDiscussions":           this.HasDiscussions,
+		"webCommitSignoffRequired": this.WebCommitSignoffRequired,
+	}
+
+	for name, pointer := range pointers {
+
+		switch v := pointer.(type) {
+		case *bool:
+			if v != nil {
+				values[name] = *v
+			}
+		case *string:
+			if v != nil {
+				values[name] = *v
+			}
+		}
+	}
+
+	return values
+}
+
+// Returns a setting's value from the repository. ok is false when GitHub
+// didn't return it, which happens for merge settings without admin access.
+func repoSetting(data *github.Repository, name string) (value any, ok bool) {
+
+	switch name {
+	case "mergeMethods":
+
+		if data.AllowMergeCommit == nil || data.AllowSquashMerge == nil || data.AllowRebaseMerge == nil {
+			return nil, false
+		}
+
+		var methods []string
+
+		if data.GetAllowMergeCommit() {
+			methods = append(methods, "merge")
+		}
+
+		if data.GetAllowRebaseMerge() {
+			methods = append(methods, "rebase")
+		}
+
+		if data.GetAllowSquashMerge() {
+			methods = append(methods, "squash")
+		}
+
+		return fmt.Sprint(methods), true
+	case "allowAutoMerge":
+		return data.GetAllowAutoMerge(), data.AllowAutoMerge != nil
+	case "deleteBranchOnMerge":
+		return data.GetDeleteBranchOnMerge(), data.DeleteBranchOnMerge != nil
+	case "allowUpdateBranch":
+		return data.GetAllowUpdateBranch(), data.AllowUpdateBranch != nil
+	case "squashMergeCommitTitle":
+		return data.GetSquashMergeCommitTitle(), data.SquashMergeCommitTitle != nil
+	case "hasIssues":
+		return data.GetHasIssues(), data.HasIssues != nil
+	case "hasWiki":
+		return data.GetHasWiki(), data.HasWiki != nil
+	case "hasProjects":
+		return data.GetHasProjects(), data.HasProjects != nil
+	case "hasDiscussions":
+		return data.GetHasDiscussions(), data.HasDiscussions != nil
+	case "webCommitSignoffRequired":
+		return data.GetWebCommitSignoffRequired(), data.WebCommitSignoffRequired != nil
+	}
+
+	return nil, false
+}
+
+// Checks the repository's settings
+type settingsCheck struct{}
+
+func init() {
+	registerCheck(settingsCheck{})
+}
+
+func (this settingsCheck) ID() string {
+	return "settings"
+}
+
+func (this settingsCheck) Description() string {
+	return "The repository's merge, feature, and housekeeping settings match the policy."
+}
+
+func (this settingsCheck) Applies(policy *PolicyFile, snapshot *repoSnapshot) bool {
+
+	for _, sPolicy := range policy.Settings {
+		if tagsMatched(sPolicy.Tags, snapshot.repo.Tags()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (this settingsCheck) Evaluate(policy *PolicyFile, snapshot *repoSnapshot) (auditResults, error) {
+
+	var results auditResults
+
+	// later policies override earlier ones
+	want := make(map[string]any)
+
+	for _, sPolicy := range policy.Settings {
+
+		if !tagsMatched(sPolicy.Tags, snapshot.repo.Tags()) {
+			continue
+		}
+
+		for name, value := range sPolicy.values() {
+			want[name] = value
+		}
+	}
+
+	for _, name := range settingNames {
+
+		wantValue, ok := want[name]
+		if !ok {
+			continue
+		}
+
+		haveValue, ok := repoSetting(snapshot.data, name)
+		if !ok {
+			results.add(snapshot.repo, RESULT_WARNING, RULE_SETTINGS_VISIBILITY, ERR_SETTINGS_VISIBILITY, name)
+			continue
+		}
+
+		if wantValue != haveValue {
+			results.add(snapshot.repo, RESULT_ERROR, RULE_SETTINGS_DIFFERENT, ERR_SETTINGS_DIFFERENT, name, wantValue, haveValue)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/exp/slices"
+)
+
+func TestSettingsCheck(t *testing.T) {
+
+	policy := &PolicyFile{Settings: []settingsPolicy{
+		{MergeMethods: []string{"squash"}, DeleteBranchOnMerge: github.Bool(true), HasWiki: github.Bool(false)},
+		{HasWiki: github.Bool(true), Tags: []string{"docs"}},
+	}}
+
+	tcs := []struct {
+		tags  []string
+		data  *github.Repository
+		rules []string
+	}{
+		{
+			data: &github.Repository{
+				AllowMergeCommit:    github.Bool(false),
+				AllowSquashMerge:    github.Bool(true),
+				AllowRebaseMerge:    github.Bool(false),
+				DeleteBranchOnMerge: github.Bool(true),
+				HasWiki:             github.Bool(false),
+			},
+			rules: nil,
+		},
+		{
+			data: &github.Repository{
+				AllowMergeCommit:    github.Bool(true),
+				AllowSquashMerge:    github.Bool(true),
+				AllowRebaseMerge:    github.Bool(false),
+				DeleteBranchOnMerge: github.Bool(false),
+				HasWiki:             github.Bool(true),
+			},
+			rules: []string{RULE_SETTINGS_DIFFERENT, RULE_SETTINGS_DIFFERENT, RULE_SETTINGS_DIFFERENT},
+		},
+		{
+			// docs repos override the wiki setting
+			tags: []string{"docs"},
+			data: &github.Repository{
+				DeleteBranchOnMerge: github.Bool(true),
+				HasWiki:             github.Bool(true),
+			},
+			rules: []string{RULE_SETTINGS_VISIBILITY},
+		},
+	}
+
+	for i, tc := range tcs {
+
+		results, err := settingsCheck{}.Evaluate(policy, testSnapshot(t, tc.tags, tc.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(resultRules(results), tc.rules) {
+			t.Errorf("Case %d: Want rules %v, got %v", i+1, tc.rules, resultRules(results))
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -29,6 +30,32 @@ func loadPolicyFile(customPath string) (*PolicyFile, []byte, error) {
 		return nil, nil, err
 	}
 
+	err = file.validateRules()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = file.validateAccess()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = file.validateAlerts()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// like the policy file itself, a custom path is used when it exists
+	dir := "."
+	if _, err := os.Stat(customPath); customPath != "" && err == nil {
+		dir = filepath.Dir(customPath)
+	}
+
+	err = file.loadManagedFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return &file, yamlContent, nil
 }
 
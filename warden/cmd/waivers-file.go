@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+
+	"github.com/repowarden/cli/warden/vcsurl"
+)
+
+//===============================================================
+// Custom types, methods, and functions needed to load and use a waivers.yml file.
+//===============================================================
+
+// The layout used for waiver expiry dates
+const WAIVER_DATE_FORMAT = "2006-01-02"
+
+// A waivers.yml file, a list of accepted exceptions to the policy
+type WaiversFile []*waiver
+
+// An accepted exception to a rule for a repository, or every repository with
+// a tag, until it expires
+type waiver struct {
+	Repository    string `yaml:"repository,omitempty" json:"repository,omitempty"`
+	Tag           string `yaml:"tag,omitempty" json:"tag,omitempty"`
+	Rule          string `yaml:"rule" json:"rule"`
+	Justification string `yaml:"justification" json:"justification"`
+	Approver      string `yaml:"approver" json:"approver"`
+	Expires       string `yaml:"expires" json:"expires"`
+
+	repo    *vcsurl.Repository
+	expires time.Time
+}
+
+// Makes sure the waiver has everything needed for an audit trail
+func (this *waiver) validate() error {
+
+	var err error
+
+	if (this.Repository == "") == (this.Tag == "") {
+		return errors.New("A waiver needs either a repository or a tag, but not both.")
+	}
+
+	if this.Repository != "" {
+		this.repo, err = vcsurl.Parse(this.Repository)
+		if err != nil {
+			return fmt.Errorf("The waiver repository %s is invalid: %s", this.Repository, err)
+		}
+	}
+
+	if _, ok := ruleMessages[this.Rule]; !ok {
+		return fmt.Errorf("The waiver rule '%s' isn't a known rule.", this.Rule)
+	}
+
+	if this.Justification == "" || this.Approver == "" {
+		return fmt.Errorf("The waiver for '%s' needs a justification and an approver.", this.Rule)
+	}
+
+	this.expires, err = time.Parse(WAIVER_DATE_FORMAT, this.Expires)
+	if err != nil {
+		return fmt.Errorf("The waiver for '%s' needs an expiry date formatted as YYYY-MM-DD.", this.Rule)
+	}
+
+	return nil
+}
+
+// Whether the waiver is still in effect at the given time. A waiver lasts
+// through the end of its expiry date.
+func (this *waiver) Active(now time.Time) bool {
+	return now.Before(this.expires.AddDate(0, 0, 1))
+}
+
+// Whether the waiver covers a result
+func (this *waiver) Matches(result auditResult) bool {
+
+	if this.Rule != result.rule {
+		return false
+	}
+
+	if this.repo != nil {
+		return this.repo.ToHTTPS() == result.repository.ToHTTPS()
+	}
+
+	return slices.Contains(result.repository.Tags(), this.Tag)
+}
+
+// Marks results covered by an active waiver as waived. Results covered only
+// by expired waivers keep their severity.
+func (this WaiversFile) apply(results auditResults, now time.Time) auditResults {
+
+	for i, result := range results {
+
+		if result.resultType == RESULT_PASS {
+			continue
+		}
+
+		for _, waiver := range this {
+
+			if waiver.Active(now) && waiver.Matches(result) {
+				results[i].resultType = RESULT_WAIVED
+				results[i].waiver = waiver
+				break
+			}
+		}
+	}
+
+	return results
+}
+
+// loadWaiversFile loads the waivers file, if there is one. Unlike the other
+// files, a missing waivers file is fine unless customPath is set.
+func loadWaiversFile(customPath string) (WaiversFile, error) {
+
+	var file WaiversFile
+
+	yamlContent, err := loadYAMLFile(customPath, "waivers.yml")
+	if err != nil {
+
+		if customPath == "" {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("'%s' was not found. The file either doesn't exist or the '--waiversFile' flag is wrong.", customPath)
+	}
+
+	err = yaml.Unmarshal(yamlContent, &file)
+	if err != nil {
+		return nil, fmt.Errorf("The waivers file couldn't be parsed. Something is wrong.")
+	}
+
+	for _, waiver := range file {
+		if err := waiver.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return file, nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaiversApply(t *testing.T) {
+
+	repo := testSnapshot(t, []string{"legacy"}, nil).repo
+	now, _ := time.Parse(WAIVER_DATE_FORMAT, "2026-06-15")
+
+	waivers := WaiversFile{
+		{Repository: "https://github.com/felicianotech/sonar.git", Rule: RULE_LICENSE_MISSING, Justification: "Archived soon", Approver: "felicianotech", Expires: "2026-06-15"},
+		{Tag: "legacy", Rule: RULE_BRANCH_DEFAULT, Justification: "Old tooling", Approver: "felicianotech", Expires: "2026-06-14"},
+		{Tag: "other", Rule: RULE_LABEL_MISSING, Justification: "Not ours", Approver: "felicianotech", Expires: "2027-01-01"},
+	}
+
+	for _, waiver := range waivers {
+		if err := waiver.validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var results auditResults
+	results.add(repo, RESULT_ERROR, RULE_LICENSE_MISSING, ERR_LICENSE_MISSING)
+	results.add(repo, RESULT_ERROR, RULE_BRANCH_DEFAULT, ERR_BRANCH_DEFAULT, "trunk", "main")
+	results.add(repo, RESULT_ERROR, RULE_LABEL_MISSING, ERR_LABEL_MISSING, "bug")
+
+	results = waivers.apply(results, now)
+
+	want := []auditResultType{RESULT_WAIVED, RESULT_ERROR, RESULT_ERROR}
+
+	for i, result := range results {
+		if result.resultType != want[i] {
+			t.Errorf("Result %d: Want %s, got %s", i+1, want[i], result.resultType)
+		}
+	}
+}
+
+func TestWaiverValidate(t *testing.T) {
+
+	tcs := []struct {
+		waiver waiver
+		valid  bool
+	}{
+		{waiver: waiver{Tag: "legacy", Rule: RULE_LICENSE_MISSING, Justification: "x", Approver: "y", Expires: "2026-01-01"}, valid: true},
+		{waiver: waiver{Rule: RULE_LICENSE_MISSING, Justification: "x", Approver: "y", Expires: "2026-01-01"}, valid: false},
+		{waiver: waiver{Tag: "legacy", Rule: "license.gone", Justification: "x", Approver: "y", Expires: "2026-01-01"}, valid: false},
+		{waiver: waiver{Tag: "legacy", Rule: RULE_LICENSE_MISSING, Expires: "2026-01-01"}, valid: false},
+		{waiver: waiver{Tag: "legacy", Rule: RULE_LICENSE_MISSING, Justification: "x", Approver: "y", Expires: "soon"}, valid: false},
+	}
+
+	for i, tc := range tcs {
+
+		if err := tc.waiver.validate(); (err == nil) != tc.valid {
+			t.Errorf("Case %d: Want valid to be %t, got error %v", i+1, tc.valid, err)
+		}
+	}
+}
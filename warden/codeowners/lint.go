@@ -0,0 +1,81 @@
+package codeowners
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A problem with a rule that GitHub won't report, such as a rule that never
+// takes effect
+type Problem struct {
+	Line    int
+	Message string
+}
+
+func (this Problem) String() string {
+	return fmt.Sprintf("line %d: %s", this.Line, this.Message)
+}
+
+// Returns the gitignore syntax a pattern uses that CODEOWNERS files don't
+// support, or an empty string if there isn't any
+func UnsupportedSyntax(pattern string) string {
+
+	switch {
+	case strings.HasPrefix(pattern, "!"):
+		return "negating a pattern with '!'"
+	case strings.HasPrefix(pattern, "#"):
+		return "escaping a pattern starting with '#'"
+	case strings.ContainsAny(pattern, "[]"):
+		return "character ranges with '[ ]'"
+	}
+
+	return ""
+}
+
+// Finds the problems with a file's rules: unsupported syntax, duplicate
+// patterns, and rules shadowed by a later rule. Since the last matching rule
+// wins, a rule whose paths are all matched by a later one never takes effect.
+func Lint(file *File) []Problem {
+
+	var problems []Problem
+
+	for i, rule := range file.Rules {
+
+		if syntax := UnsupportedSyntax(rule.Pattern); syntax != "" {
+			problems = append(problems, Problem{rule.Line, fmt.Sprintf("the pattern '%s' uses %s, which CODEOWNERS doesn't support", rule.Pattern, syntax)})
+			continue
+		}
+
+		for _, later := range file.Rules[i+1:] {
+
+			if later.Pattern == rule.Pattern {
+				problems = append(problems, Problem{rule.Line, fmt.Sprintf("the pattern '%s' is repeated on line %d, which overrides it", rule.Pattern, later.Line)})
+				break
+			}
+
+			if shadows(later.Pattern, rule.Pattern) {
+				problems = append(problems, Problem{rule.Line, fmt.Sprintf("the pattern '%s' is shadowed by '%s' on line %d and never takes effect", rule.Pattern, later.Pattern, later.Line)})
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+// Whether every path matched by a pattern is also matched by a later one.
+// This only recognizes the common cases, a catch-all or an anchored directory
+// containing the earlier pattern, so some shadowed rules aren't found.
+func shadows(later, earlier string) bool {
+
+	if (Rule{Pattern: later}).CatchAll() {
+		return true
+	}
+
+	dir := strings.TrimSuffix(later, "**")
+	if !strings.HasPrefix(dir, "/") || !strings.HasSuffix(dir, "/") || strings.ContainsAny(dir, "*?") {
+		return false
+	}
+
+	return strings.HasPrefix(earlier, dir) && earlier != dir
+}
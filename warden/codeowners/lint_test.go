@@ -0,0 +1,60 @@
+package codeowners
+
+import "testing"
+
+func TestLint(t *testing.T) {
+
+	file, err := Parse(`/docs/api/   @felicianotech/api
+/docs/*.md   @felicianotech/writers
+/docs/       @felicianotech/writers
+/src/        @felicianotech/dev
+/scripts/    @felicianotech/dev
+!/vendor/    @felicianotech/dev
+/src/        @felicianotech/platform
+*.go         @felicianotech/go
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		line int
+		kind string
+	}{
+		{line: 1, kind: "shadowed"},
+		{line: 2, kind: "shadowed"},
+		{line: 4, kind: "repeated"},
+		{line: 6, kind: "unsupported"},
+	}
+
+	problems := Lint(file)
+
+	if len(problems) != len(tcs) {
+		t.Fatalf("Want %d problems, got %v", len(tcs), problems)
+	}
+
+	for i, tc := range tcs {
+		if problems[i].Line != tc.line {
+			t.Errorf("Problem %d: Want a %s rule on line %d, got %s", i+1, tc.kind, tc.line, problems[i])
+		}
+	}
+}
+
+func TestUnsupportedSyntax(t *testing.T) {
+
+	tcs := []struct {
+		pattern     string
+		unsupported bool
+	}{
+		{pattern: "/docs/**/*.md"},
+		{pattern: "!/docs/", unsupported: true},
+		{pattern: "/src/[ab]*", unsupported: true},
+		{pattern: "#notes", unsupported: true},
+	}
+
+	for i, tc := range tcs {
+		if (UnsupportedSyntax(tc.pattern) != "") != tc.unsupported {
+			t.Errorf("Case %d: Want unsupported %t for '%s'", i+1, tc.unsupported, tc.pattern)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package codeowners
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The locations GitHub looks for a CODEOWNERS file, in the order it looks.
+// Only the first file found is used.
+var Paths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+var (
+	userRE  = regexp.MustCompile(`^@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?$`)
+	teamRE  = regexp.MustCompile(`^@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?/[A-Za-z0-9_.-]+$`)
+	emailRE = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// A line of a CODEOWNERS file that assigns owners to a pattern. A rule
+// without owners leaves the matching paths unowned.
+type Rule struct {
+	Line    int
+	Pattern string
+	Owners  []string
+}
+
+// Whether the rule matches every path in the repository
+func (this Rule) CatchAll() bool {
+
+	switch this.Pattern {
+	case "*", "**", "/*", "/**":
+		return true
+	}
+
+	return false
+}
+
+// A parsed CODEOWNERS file
+type File struct {
+	Rules []Rule
+}
+
+// Returns the rules for a pattern, in the order they appear
+func (this *File) RulesFor(pattern string) []Rule {
+
+	var rules []Rule
+
+	for _, rule := range this.Rules {
+		if rule.Pattern == pattern {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// Whether the file has a rule that matches every path
+func (this *File) HasCatchAll() bool {
+
+	for _, rule := range this.Rules {
+		if rule.CatchAll() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A problem with a line of a CODEOWNERS file
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (this ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", this.Line, this.Message)
+}
+
+// Every problem found while parsing a CODEOWNERS file
+type ParseErrors []ParseError
+
+func (this ParseErrors) Error() string {
+
+	var messages []string
+
+	for _, err := range this {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Whether an owner is a team, such as '@org/team'
+func IsTeam(owner string) bool {
+	return teamRE.MatchString(owner)
+}
+
+// Whether an owner is a user, such as '@octocat'
+func IsUser(owner string) bool {
+	return userRE.MatchString(owner)
+}
+
+// Whether an owner is an email address
+func IsEmail(owner string) bool {
+	return emailRE.MatchString(owner)
+}
+
+// Parses the content of a CODEOWNERS file. Lines that can't be parsed are
+// left out of the file and returned as ParseErrors, so the file is usable
+// even when an error is returned.
+func Parse(content string) (*File, error) {
+
+	file := &File{}
+	var errs ParseErrors
+
+	for i, line := range strings.Split(content, "\n") {
+
+		fields := strings.Fields(stripComment(line))
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule := Rule{
+			Line:    i + 1,
+			Pattern: strings.ReplaceAll(fields[0], `\#`, "#"),
+		}
+
+		valid := true
+
+		for _, owner := range fields[1:] {
+
+			if !IsTeam(owner) && !IsUser(owner) && !IsEmail(owner) {
+				errs = append(errs, ParseError{i + 1, fmt.Sprintf("'%s' isn't a valid owner", owner)})
+				valid = false
+				continue
+			}
+
+			rule.Owners = append(rule.Owners, owner)
+		}
+
+		if valid {
+			file.Rules = append(file.Rules, rule)
+		}
+	}
+
+	if len(errs) > 0 {
+		return file, errs
+	}
+
+	return file, nil
+}
+
+// Removes a comment from a line. Comments start with a '#' that isn't
+// escaped with a backslash.
+func stripComment(line string) string {
+
+	for i := 0; i < len(line); i++ {
+
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+
+		if line[i] == '#' {
+			return line[:i]
+		}
+	}
+
+	return line
+}
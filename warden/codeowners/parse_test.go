@@ -0,0 +1,77 @@
+package codeowners
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestParse(t *testing.T) {
+
+	content := `# The platform team owns everything by default
+*                   @felicianotech/platform
+
+/docs/              @felicianotech/writers docs@example.com # inline comment
+\#notes             @felicianotech
+/vendor/
+/build/             felicianotech
+`
+
+	file, err := Parse(content)
+
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 1 || parseErrs[0].Line != 7 {
+		t.Fatalf("Want a single parse error on line 7, got %v", err)
+	}
+
+	tcs := []struct {
+		pattern string
+		owners  []string
+		line    int
+	}{
+		{pattern: "*", owners: []string{"@felicianotech/platform"}, line: 2},
+		{pattern: "/docs/", owners: []string{"@felicianotech/writers", "docs@example.com"}, line: 4},
+		{pattern: "#notes", owners: []string{"@felicianotech"}, line: 5},
+		{pattern: "/vendor/", owners: nil, line: 6},
+	}
+
+	if len(file.Rules) != len(tcs) {
+		t.Fatalf("Want %d rules, got %d", len(tcs), len(file.Rules))
+	}
+
+	for i, tc := range tcs {
+
+		rule := file.Rules[i]
+
+		if rule.Pattern != tc.pattern || rule.Line != tc.line || !slices.Equal(rule.Owners, tc.owners) {
+			t.Errorf("Rule %d: Want '%s' on line %d owned by %v, got '%s' on line %d owned by %v", i+1, tc.pattern, tc.line, tc.owners, rule.Pattern, rule.Line, rule.Owners)
+		}
+	}
+
+	if !file.HasCatchAll() {
+		t.Error("Want the file to have a catch-all rule.")
+	}
+}
+
+func TestOwnerKinds(t *testing.T) {
+
+	tcs := []struct {
+		owner string
+		team  bool
+		user  bool
+		email bool
+	}{
+		{owner: "@felicianotech/platform", team: true},
+		{owner: "@felicianotech", user: true},
+		{owner: "docs@example.com", email: true},
+		{owner: "felicianotech"},
+	}
+
+	for i, tc := range tcs {
+
+		if IsTeam(tc.owner) != tc.team || IsUser(tc.owner) != tc.user || IsEmail(tc.owner) != tc.email {
+			t.Errorf("Owner %d: '%s' was classified incorrectly.", i+1, tc.owner)
+		}
+	}
+}